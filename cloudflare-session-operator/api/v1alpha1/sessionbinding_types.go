@@ -1,7 +1,9 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // SessionBindingPhase represents the lifecycle phase of a session binding.
@@ -12,6 +14,9 @@ const (
 	SessionBindingPhaseBound   SessionBindingPhase = "Bound"
 	SessionBindingPhaseExpired SessionBindingPhase = "Expired"
 	SessionBindingPhaseError   SessionBindingPhase = "Error"
+	// SessionBindingPhaseFailed is terminal: the binding exceeded the operator's
+	// retry limit and is not retried until its spec changes.
+	SessionBindingPhaseFailed SessionBindingPhase = "Failed"
 )
 
 // SessionBindingSpec defines the desired state of SessionBinding.
@@ -22,8 +27,25 @@ type SessionBindingSpec struct {
 	// +optional
 	UserID string `json:"userID,omitempty"`
 	// TargetDeployment references the deployment that should be cloned for session pods.
-	TargetDeployment string `json:"targetDeployment"`
+	// Exactly one of TargetDeployment and TargetSelector must be set.
+	// +optional
+	TargetDeployment string `json:"targetDeployment,omitempty"`
+	// TargetSelector selects an existing ready pod to route the session to, for
+	// workloads not managed by a Deployment (bare pods, StatefulSets). No pod is created.
+	// +optional
+	TargetSelector *metav1.LabelSelector `json:"targetSelector,omitempty"`
+	// TargetPort is the container port to route to, by name (e.g. "http") or number.
+	// Defaults to the first declared container port, or 80.
+	// +optional
+	TargetPort *intstr.IntOrString `json:"targetPort,omitempty"`
+	// CredentialsSecretRef names a Secret in the binding's namespace holding the
+	// Cloudflare credentials to use instead of the operator's own, under the keys
+	// account_id, api_token, kv_namespace_id and optionally api_base_url.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
 	// TTLSeconds defines how long the binding should remain active after creation.
+	// When unset the operator's --default-ttl-seconds applies; an explicit 0 means
+	// the binding never expires.
 	// +optional
 	TTLSeconds *int64 `json:"ttlSeconds,omitempty"`
 }
@@ -33,18 +55,40 @@ type SessionBindingStatus struct {
 	Phase SessionBindingPhase `json:"phase,omitempty"`
 	// BoundPod is the name of the pod created for this session.
 	BoundPod string `json:"boundPod,omitempty"`
-	// RouteEndpoint is the endpoint programmed in Cloudflare for this session.
+	// RouteEndpoint is the endpoint last programmed in Cloudflare for this session.
+	// It is kept while the binding waits for a pod so endpoint changes can be reported.
 	RouteEndpoint string `json:"routeEndpoint,omitempty"`
-	// ObservedGeneration tracks the latest processed generation.
+	// ReadyReplicas is the number of ready pods backing the binding's target.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// TotalReplicas is the number of pods backing the binding's target, ready or not.
+	TotalReplicas int32 `json:"totalReplicas,omitempty"`
+	// ObservedGeneration is the generation of the spec last reconciled successfully.
+	// It lags metadata.generation while a spec change is still being processed.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// Conditions represent the latest available observations of the binding state.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// LastReconcileTime records the last time the controller reconciled the resource.
 	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+	// ConsecutiveFailures counts reconciles that have failed in a row; it resets once
+	// the binding is bound and drives the error requeue backoff.
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// SessionNotFoundSince records when Cloudflare first reported the session missing.
+	// The binding only expires once the session stays missing for the operator's
+	// grace period; it is cleared as soon as the session is found again.
+	// +optional
+	SessionNotFoundSince *metav1.Time `json:"sessionNotFoundSince,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="SessionID",type=string,JSONPath=`.spec.sessionID`
+//+kubebuilder:printcolumn:name="TargetDeployment",type=string,JSONPath=`.spec.targetDeployment`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.status.routeEndpoint`
+//+kubebuilder:printcolumn:name="Ready Replicas",type=integer,JSONPath=`.status.readyReplicas`,priority=1
+//+kubebuilder:printcolumn:name="Total Replicas",type=integer,JSONPath=`.status.totalReplicas`,priority=1
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // SessionBinding is the Schema for the sessionbindings API.
 type SessionBinding struct {
@@ -69,8 +113,11 @@ func init() {
 }
 
 const (
-	// Condition types for status management.
+	// Condition types for status management. ConditionReady summarizes the others
+	// and is True only while the binding is Bound.
+	ConditionReady             = "Ready"
 	ConditionSessionDiscovered = "SessionDiscovered"
 	ConditionPodReady          = "PodReady"
 	ConditionRouteConfigured   = "RouteConfigured"
+	ConditionStalled           = "Stalled"
 )
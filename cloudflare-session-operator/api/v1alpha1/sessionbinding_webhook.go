@@ -0,0 +1,110 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/names"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SessionBindingWebhook defaults and validates SessionBindings at admission.
+type SessionBindingWebhook struct {
+	// DefaultTTLSeconds is stored on bindings created without ttlSeconds, so the
+	// effective TTL is visible on the object. Zero leaves ttlSeconds unset.
+	DefaultTTLSeconds int64
+}
+
+var (
+	_ admission.CustomDefaulter = &SessionBindingWebhook{}
+	_ admission.CustomValidator = &SessionBindingWebhook{}
+)
+
+// SetupWebhookWithManager registers the SessionBinding admission webhooks.
+func (w *SessionBindingWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&SessionBinding{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-cloudflare-example-com-v1alpha1-sessionbinding,mutating=true,failurePolicy=fail,sideEffects=None,groups=cloudflare.example.com,resources=sessionbindings,verbs=create;update,versions=v1alpha1,name=msessionbinding.cloudflare.example.com,admissionReviewVersions=v1
+
+// Default fills in the operator's default TTL and trims stray whitespace from the
+// session ID. TargetPort is left alone; the reconciler resolves it from the pod.
+func (w *SessionBindingWebhook) Default(_ context.Context, obj runtime.Object) error {
+	binding, err := asSessionBinding(obj)
+	if err != nil {
+		return err
+	}
+	binding.Spec.SessionID = strings.TrimSpace(binding.Spec.SessionID)
+	if binding.Spec.TTLSeconds == nil && w.DefaultTTLSeconds > 0 {
+		ttl := w.DefaultTTLSeconds
+		binding.Spec.TTLSeconds = &ttl
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-cloudflare-example-com-v1alpha1-sessionbinding,mutating=false,failurePolicy=fail,sideEffects=None,groups=cloudflare.example.com,resources=sessionbindings,verbs=create;update,versions=v1alpha1,name=vsessionbinding.cloudflare.example.com,admissionReviewVersions=v1
+
+// ValidateCreate rejects invalid specs at admission instead of at reconcile time.
+func (w *SessionBindingWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	binding, err := asSessionBinding(obj)
+	if err != nil {
+		return nil, err
+	}
+	return nil, binding.validate()
+}
+
+// ValidateUpdate applies the same checks as ValidateCreate.
+func (w *SessionBindingWebhook) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return w.ValidateCreate(ctx, newObj)
+}
+
+// ValidateDelete allows all deletes.
+func (w *SessionBindingWebhook) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func asSessionBinding(obj runtime.Object) (*SessionBinding, error) {
+	binding, ok := obj.(*SessionBinding)
+	if !ok {
+		return nil, fmt.Errorf("expected a SessionBinding but got %T", obj)
+	}
+	return binding, nil
+}
+
+func (r *SessionBinding) validate() error {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	switch {
+	case r.Spec.SessionID == "":
+		errs = append(errs, field.Required(spec.Child("sessionID"), "sessionID must not be empty"))
+	case names.SessionPodName(r.Spec.SessionID) == names.UnknownSessionPodName:
+		errs = append(errs, field.Invalid(spec.Child("sessionID"), r.Spec.SessionID,
+			fmt.Sprintf("sessionID must contain a letter or digit and must not map to the reserved pod name %q", names.UnknownSessionPodName)))
+	}
+
+	if r.Spec.TTLSeconds != nil && *r.Spec.TTLSeconds < 0 {
+		errs = append(errs, field.Invalid(spec.Child("ttlSeconds"), *r.Spec.TTLSeconds, "ttlSeconds must not be negative"))
+	}
+
+	hasDeployment := r.Spec.TargetDeployment != ""
+	hasSelector := r.Spec.TargetSelector != nil
+	if hasDeployment == hasSelector {
+		errs = append(errs, field.Invalid(spec.Child("targetDeployment"), r.Spec.TargetDeployment,
+			"exactly one of targetDeployment or targetSelector must be set"))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("SessionBinding").GroupKind(), r.Name, errs)
+}
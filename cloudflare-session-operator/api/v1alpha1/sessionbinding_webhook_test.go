@@ -0,0 +1,110 @@
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSessionBindingValidate(t *testing.T) {
+	t.Parallel()
+	ttl := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name      string
+		spec      SessionBindingSpec
+		errSubstr string
+	}{
+		{
+			name: "valid deployment target",
+			spec: SessionBindingSpec{SessionID: "abc", TargetDeployment: "web", TTLSeconds: ttl(600)},
+		},
+		{
+			name: "valid selector target",
+			spec: SessionBindingSpec{SessionID: "abc", TargetSelector: &metav1.LabelSelector{}},
+		},
+		{
+			name:      "empty session ID",
+			spec:      SessionBindingSpec{TargetDeployment: "web"},
+			errSubstr: "spec.sessionID: Required",
+		},
+		{
+			name:      "session ID sanitizes to fallback",
+			spec:      SessionBindingSpec{SessionID: "__", TargetDeployment: "web"},
+			errSubstr: "spec.sessionID: Invalid",
+		},
+		{
+			name:      "negative TTL",
+			spec:      SessionBindingSpec{SessionID: "abc", TargetDeployment: "web", TTLSeconds: ttl(-1)},
+			errSubstr: "spec.ttlSeconds",
+		},
+		{
+			name:      "both targets",
+			spec:      SessionBindingSpec{SessionID: "abc", TargetDeployment: "web", TargetSelector: &metav1.LabelSelector{}},
+			errSubstr: "exactly one of targetDeployment or targetSelector",
+		},
+		{
+			name:      "no target",
+			spec:      SessionBindingSpec{SessionID: "abc"},
+			errSubstr: "exactly one of targetDeployment or targetSelector",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &SessionBindingWebhook{}
+			binding := &SessionBinding{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: tt.spec}
+			_, createErr := w.ValidateCreate(context.Background(), binding)
+			_, updateErr := w.ValidateUpdate(context.Background(), binding.DeepCopy(), binding)
+			for _, err := range []error{createErr, updateErr} {
+				if tt.errSubstr == "" {
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+					}
+					continue
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+					t.Errorf("error = %v, want containing %q", err, tt.errSubstr)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionBindingDefault(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	w := &SessionBindingWebhook{DefaultTTLSeconds: 3600}
+	binding := &SessionBinding{Spec: SessionBindingSpec{SessionID: "  abc-123 \n"}}
+	if err := w.Default(ctx, binding); err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if binding.Spec.SessionID != "abc-123" {
+		t.Errorf("SessionID = %q, want %q", binding.Spec.SessionID, "abc-123")
+	}
+	if binding.Spec.TTLSeconds == nil || *binding.Spec.TTLSeconds != 3600 {
+		t.Errorf("TTLSeconds = %v, want 3600", binding.Spec.TTLSeconds)
+	}
+
+	zero := int64(0)
+	explicit := &SessionBinding{Spec: SessionBindingSpec{SessionID: "abc", TTLSeconds: &zero}}
+	if err := w.Default(ctx, explicit); err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if *explicit.Spec.TTLSeconds != 0 {
+		t.Errorf("explicit TTLSeconds = %d, want it left at 0", *explicit.Spec.TTLSeconds)
+	}
+
+	unset := &SessionBinding{Spec: SessionBindingSpec{SessionID: "abc"}}
+	if err := (&SessionBindingWebhook{}).Default(ctx, unset); err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if unset.Spec.TTLSeconds != nil {
+		t.Errorf("TTLSeconds = %d, want nil without an operator default", *unset.Spec.TTLSeconds)
+	}
+
+	if err := w.Default(ctx, &SessionBindingList{}); err == nil {
+		t.Errorf("Default() accepted a non-SessionBinding object")
+	}
+}
@@ -5,8 +5,10 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -118,6 +120,21 @@ func (in *CloudflareOperatorConfigStatus) DeepCopyInto(out *CloudflareOperatorCo
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SessionBindingSpec) DeepCopyInto(out *SessionBindingSpec) {
 	*out = *in
+	if in.TargetSelector != nil {
+		in, out := &in.TargetSelector, &out.TargetSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TargetPort != nil {
+		in, out := &in.TargetPort, &out.TargetPort
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	if in.TTLSeconds != nil {
 		in, out := &in.TTLSeconds, &out.TTLSeconds
 		*out = new(int64)
@@ -139,6 +156,10 @@ func (in *SessionBindingStatus) DeepCopyInto(out *SessionBindingStatus) {
 		in, out := &in.LastReconcileTime, &out.LastReconcileTime
 		*out = (*in).DeepCopy()
 	}
+	if in.SessionNotFoundSince != nil {
+		in, out := &in.SessionNotFoundSince, &out.SessionNotFoundSince
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Creme-ala-creme/cloudflare-session-operator/api/v1alpha1"
+	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/cloudflare"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Keys read from a binding's credentialsSecretRef. They match the operator's own
+// credentials Secret.
+const (
+	secretKeyAccountID   = "account_id"
+	secretKeyAPIToken    = "api_token"
+	secretKeyKVNamespace = "kv_namespace_id"
+	secretKeyAPIBaseURL  = "api_base_url"
+)
+
+// CloudflareCredentials are the tenant credentials read from a binding's Secret.
+// BaseURL is optional.
+type CloudflareCredentials struct {
+	AccountID   string
+	APIToken    string
+	KVNamespace string
+	BaseURL     string
+}
+
+// ClientFactory builds a Cloudflare client for tenant credentials.
+type ClientFactory func(CloudflareCredentials) cloudflare.Client
+
+// clientCache holds per-Secret clients keyed by the Secret's resourceVersion, so a
+// rotated Secret gets a fresh client on the next reconcile.
+type clientCache struct {
+	mu      sync.Mutex
+	clients map[types.NamespacedName]cachedClient
+}
+
+type cachedClient struct {
+	resourceVersion string
+	client          cloudflare.Client
+}
+
+// cloudflareClientFor returns the client for the binding: one built from its
+// credentialsSecretRef, or the operator-wide CFClient when it has none.
+func (r *SessionBindingReconciler) cloudflareClientFor(ctx context.Context, binding *v1alpha1.SessionBinding) (cloudflare.Client, error) {
+	ref := binding.Spec.CredentialsSecretRef
+	if ref == nil {
+		return r.CFClient, nil
+	}
+	if r.NewCFClient == nil {
+		return nil, fmt.Errorf("credentialsSecretRef %q is set but the operator cannot build per-binding clients", ref.Name)
+	}
+
+	// Read through the API reader so the operator does not cache every Secret.
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	key := types.NamespacedName{Namespace: binding.Namespace, Name: ref.Name}
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("reading credentials secret %q: %w", ref.Name, err)
+	}
+
+	r.clients.mu.Lock()
+	defer r.clients.mu.Unlock()
+	if cached, ok := r.clients.clients[key]; ok && cached.resourceVersion == secret.ResourceVersion {
+		return cached.client, nil
+	}
+
+	creds, err := credentialsFromSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	cf := r.NewCFClient(creds)
+	if r.clients.clients == nil {
+		r.clients.clients = map[types.NamespacedName]cachedClient{}
+	}
+	r.clients.clients[key] = cachedClient{resourceVersion: secret.ResourceVersion, client: cf}
+	return cf, nil
+}
+
+// credentialsFromSecret reads the credential keys, reporting all missing ones.
+func credentialsFromSecret(secret *corev1.Secret) (CloudflareCredentials, error) {
+	creds := CloudflareCredentials{
+		AccountID:   string(secret.Data[secretKeyAccountID]),
+		APIToken:    string(secret.Data[secretKeyAPIToken]),
+		KVNamespace: string(secret.Data[secretKeyKVNamespace]),
+		BaseURL:     string(secret.Data[secretKeyAPIBaseURL]),
+	}
+	var missing []string
+	for _, kv := range []struct{ key, value string }{
+		{secretKeyAccountID, creds.AccountID},
+		{secretKeyAPIToken, creds.APIToken},
+		{secretKeyKVNamespace, creds.KVNamespace},
+	} {
+		if kv.value == "" {
+			missing = append(missing, kv.key)
+		}
+	}
+	if len(missing) > 0 {
+		return CloudflareCredentials{}, fmt.Errorf("credentials secret %q is missing keys: %s", secret.Name, strings.Join(missing, ", "))
+	}
+	return creds, nil
+}
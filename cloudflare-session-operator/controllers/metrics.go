@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Creme-ala-creme/cloudflare-session-operator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	readyDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "sessionbinding_ready_duration_seconds",
+		Help:    "Time from SessionBinding creation until its route was first programmed.",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12),
+	})
+	activeBindings = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sessionbindings_active",
+		Help: "Number of SessionBindings currently in the Bound phase.",
+	})
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sessionbinding_reconcile_total",
+		Help: "SessionBinding reconciles by outcome.",
+	}, []string{"result"})
+)
+
+// Values of the result label on sessionbinding_reconcile_total.
+const (
+	reconcileResultCreated  = "created"
+	reconcileResultUpdated  = "updated"
+	reconcileResultExpired  = "expired"
+	reconcileResultError    = "error"
+	reconcileResultNotFound = "notfound"
+)
+
+func init() {
+	metrics.Registry.MustRegister(readyDuration, activeBindings, reconcileTotal)
+}
+
+// reconcileResult classifies a finished reconcile: created when the binding became
+// Bound, expired, error for failures (including the terminal Failed phase), and
+// updated for everything else.
+func reconcileResult(binding *v1alpha1.SessionBinding, wasBound bool, err error) string {
+	if err != nil {
+		return reconcileResultError
+	}
+	switch binding.Status.Phase {
+	case v1alpha1.SessionBindingPhaseBound:
+		if !wasBound {
+			return reconcileResultCreated
+		}
+	case v1alpha1.SessionBindingPhaseExpired:
+		return reconcileResultExpired
+	case v1alpha1.SessionBindingPhaseError, v1alpha1.SessionBindingPhaseFailed:
+		return reconcileResultError
+	}
+	return reconcileResultUpdated
+}
+
+// bindingMetrics tracks which bindings are bound so the active gauge can be set
+// from a single source of truth, and which have already reported their
+// time-to-ready so it is observed once per binding rather than on every re-bind.
+// State is in-memory; after a restart every binding is reconciled again and the
+// active set is rebuilt.
+var bindingMetrics = struct {
+	sync.Mutex
+	bound         map[types.NamespacedName]bool
+	readyObserved map[types.NamespacedName]bool
+}{
+	bound:         map[types.NamespacedName]bool{},
+	readyObserved: map[types.NamespacedName]bool{},
+}
+
+// recordBindingMetrics updates the metrics after a reconcile. wasBound is the phase
+// before the reconcile; a binding already Bound when first seen (e.g. after an
+// operator restart) does not report a time-to-ready.
+func recordBindingMetrics(key types.NamespacedName, binding *v1alpha1.SessionBinding, wasBound bool, now time.Time) {
+	bindingMetrics.Lock()
+	defer bindingMetrics.Unlock()
+
+	bound := binding.Status.Phase == v1alpha1.SessionBindingPhaseBound
+	if bound && !wasBound && !bindingMetrics.readyObserved[key] {
+		readyDuration.Observe(now.Sub(binding.CreationTimestamp.Time).Seconds())
+		bindingMetrics.readyObserved[key] = true
+	}
+	if bound {
+		bindingMetrics.bound[key] = true
+	} else {
+		delete(bindingMetrics.bound, key)
+	}
+	activeBindings.Set(float64(len(bindingMetrics.bound)))
+}
+
+// forgetBindingMetrics drops a deleted binding from the metrics state.
+func forgetBindingMetrics(key types.NamespacedName) {
+	bindingMetrics.Lock()
+	defer bindingMetrics.Unlock()
+	delete(bindingMetrics.bound, key)
+	delete(bindingMetrics.readyObserved, key)
+	activeBindings.Set(float64(len(bindingMetrics.bound)))
+}
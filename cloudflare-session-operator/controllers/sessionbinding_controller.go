@@ -2,11 +2,19 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/Creme-ala-creme/cloudflare-session-operator/api/v1alpha1"
+	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/clock"
 	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/cloudflare"
+	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/names"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -14,18 +22,45 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
 	sessionBindingFinalizer = "sessionbinding.cloudflare.example.com/finalizer"
 	podSessionLabelKey      = "cloudflare.example.com/session-id"
+
+	// errorRequeueBase and errorRequeueMax bound the backoff for failing bindings:
+	// after n consecutive failures the binding requeues after
+	// min(errorRequeueBase*2^n, errorRequeueMax).
+	errorRequeueBase = 30 * time.Second
+	errorRequeueMax  = 15 * time.Minute
+
+	// defaultPodReadyRequeueInterval is how often a binding waiting for a ready pod
+	// is rechecked when PodReadyRequeueInterval is unset.
+	defaultPodReadyRequeueInterval = 5 * time.Second
+
+	// missingCredentialsRequeue is how often a binding is rechecked while the
+	// Cloudflare client has no credentials; only a config change can fix it.
+	missingCredentialsRequeue = 10 * time.Minute
+
+	// circuitOpenRequeue is how long a binding waits while the Cloudflare client's
+	// circuit breaker is open, i.e. while Cloudflare is known to be failing.
+	circuitOpenRequeue = 5 * time.Minute
+
+	// ttlRequeueJitter is the fraction by which the requeue-until-expiry interval
+	// of a bound binding is randomly lengthened. It is never shortened: waking
+	// before expiry would only cost another Cloudflare round-trip and requeue.
+	ttlRequeueJitter = 0.1
 )
 
 // SessionBindingReconciler reconciles a SessionBinding object
@@ -35,6 +70,42 @@ type SessionBindingReconciler struct {
 	CFClient cloudflare.Client
 	Recorder recordEventRecorder
 	Clock    Clock
+	// MaxRetries is the number of consecutive failures after which a binding moves
+	// to the terminal Failed phase. Zero retries forever.
+	MaxRetries int32
+	// DefaultTTL applies to bindings that leave TTLSeconds unset. Zero means such
+	// bindings never expire.
+	DefaultTTL time.Duration
+	// ExpiryGracePeriod is how long a session must stay missing in Cloudflare before
+	// the binding is marked Expired, to ride out propagation delays. Zero expires
+	// on the first not-found result.
+	ExpiryGracePeriod time.Duration
+	// PodReadyRequeueInterval is the fixed requeue interval while waiting for a
+	// target pod to become ready. Zero uses defaultPodReadyRequeueInterval.
+	PodReadyRequeueInterval time.Duration
+	// ReconcileTimeout bounds the work done in a single reconcile so a hung
+	// Cloudflare call (and its retries) cannot block a worker. Zero disables it.
+	ReconcileTimeout time.Duration
+	// ResyncPeriod is how often a bound binding is reconciled again so routes
+	// changed or deleted out of band in Workers KV are repaired. Only bindings are
+	// requeued; pod and deployment informers are not resynced. Zero disables it.
+	ResyncPeriod time.Duration
+	// NewCFClient builds clients for bindings with a credentialsSecretRef. Bindings
+	// without one use CFClient.
+	NewCFClient ClientFactory
+	// APIReader reads credentials Secrets uncached; nil falls back to Client.
+	APIReader client.Reader
+	// Jitter returns a number in [0, 1) used to spread TTL-expiry requeues; nil
+	// uses math/rand.
+	Jitter func() float64
+
+	clients clientCache
+}
+
+// credentialChecker is implemented by Cloudflare clients that can report missing
+// credentials up front (see cloudflare.APIClient.HasCredentials).
+type credentialChecker interface {
+	HasCredentials() bool
 }
 
 type recordEventRecorder interface {
@@ -42,14 +113,10 @@ type recordEventRecorder interface {
 }
 
 // Clock abstracts time-related functionality for easier testing.
-type Clock interface {
-	Now() time.Time
-}
+type Clock = clock.Clock
 
 // RealClock implements Clock using the standard library.
-type RealClock struct{}
-
-func (RealClock) Now() time.Time { return time.Now() }
+type RealClock = clock.Real
 
 //+kubebuilder:rbac:groups=cloudflare.example.com,resources=sessionbindings,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cloudflare.example.com,resources=sessionbindings/status,verbs=get;update;patch
@@ -57,16 +124,22 @@ func (RealClock) Now() time.Time { return time.Now() }
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
 
 func (r *SessionBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
 	binding := &v1alpha1.SessionBinding{}
 	if err := r.Get(ctx, req.NamespacedName, binding); err != nil {
+		if apierrors.IsNotFound(err) {
+			forgetBindingMetrics(req.NamespacedName)
+			reconcileTotal.WithLabelValues(reconcileResultNotFound).Inc()
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
 	if !binding.ObjectMeta.DeletionTimestamp.IsZero() {
+		forgetBindingMetrics(req.NamespacedName)
 		return r.handleDeletion(ctx, logger, binding)
 	}
 
@@ -77,18 +150,64 @@ func (r *SessionBindingReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	binding.Status.ObservedGeneration = binding.Generation
+	if binding.Status.Phase == v1alpha1.SessionBindingPhaseFailed {
+		if binding.Status.ObservedGeneration == binding.Generation {
+			return ctrl.Result{}, nil
+		}
+		// The spec changed since we gave up; start over.
+		binding.Status.ConsecutiveFailures = 0
+		meta.RemoveStatusCondition(&binding.Status.Conditions, v1alpha1.ConditionStalled)
+	}
+
+	wasBound := binding.Status.Phase == v1alpha1.SessionBindingPhaseBound
 	now := metav1.Time{Time: r.Clock.Now()}
 	binding.Status.LastReconcileTime = &now
 
-	result, reconcileErr := r.reconcileActive(ctx, logger, binding)
+	result, reconcileErr := r.reconcileWithTimeout(ctx, logger, binding)
+	if r.MaxRetries > 0 && binding.Status.ConsecutiveFailures > r.MaxRetries {
+		r.giveUp(logger, binding, reconcileErr)
+		result, reconcileErr = ctrl.Result{}, nil
+	}
+	// Only a reconcile that settled the spec records its generation, so
+	// metadata.generation != status.observedGeneration means work is pending.
+	if reconcileErr == nil && binding.Status.Phase != v1alpha1.SessionBindingPhaseError {
+		binding.Status.ObservedGeneration = binding.Generation
+	}
+	r.setReadyCondition(binding)
+	recordBindingMetrics(req.NamespacedName, binding, wasBound, r.Clock.Now())
 	statusErr := r.patchStatus(ctx, binding)
+	reconcileTotal.WithLabelValues(reconcileResult(binding, wasBound, errors.Join(reconcileErr, statusErr))).Inc()
 	if reconcileErr != nil {
 		return result, reconcileErr
 	}
 	return result, statusErr
 }
 
+// reconcileWithTimeout runs reconcileActive under ReconcileTimeout. A reconcile that
+// runs out of time counts as one failure and requeues with the error backoff; the
+// status is still patched with the caller's context.
+func (r *SessionBindingReconciler) reconcileWithTimeout(ctx context.Context, logger logr.Logger, binding *v1alpha1.SessionBinding) (ctrl.Result, error) {
+	if r.ReconcileTimeout <= 0 {
+		return r.reconcileActive(ctx, logger, binding)
+	}
+	reconcileCtx, cancel := context.WithTimeout(ctx, r.ReconcileTimeout)
+	defer cancel()
+
+	failuresBefore := binding.Status.ConsecutiveFailures
+	result, err := r.reconcileActive(reconcileCtx, logger, binding)
+	if !errors.Is(reconcileCtx.Err(), context.DeadlineExceeded) || binding.Status.Phase == v1alpha1.SessionBindingPhaseBound {
+		return result, err
+	}
+
+	logger.Info("reconcile timed out", "sessionID", binding.Spec.SessionID, "timeout", r.ReconcileTimeout.String())
+	r.Recorder.Event(binding, corev1.EventTypeWarning, "ReconcileTimeout",
+		fmt.Sprintf("Reconcile did not finish within %s", r.ReconcileTimeout))
+	if binding.Status.ConsecutiveFailures == failuresBefore {
+		return ctrl.Result{RequeueAfter: recordFailure(binding)}, nil
+	}
+	return ctrl.Result{RequeueAfter: errorRequeueInterval(binding.Status.ConsecutiveFailures)}, nil
+}
+
 func (r *SessionBindingReconciler) reconcileActive(ctx context.Context, logger logr.Logger, binding *v1alpha1.SessionBinding) (ctrl.Result, error) {
 	// Validate sessionID format (defense-in-depth alongside CRD validation).
 	if err := cloudflare.ValidateSessionID(binding.Spec.SessionID); err != nil {
@@ -98,82 +217,278 @@ func (r *SessionBindingReconciler) reconcileActive(ctx context.Context, logger l
 		return ctrl.Result{}, nil
 	}
 
+	if err := validateTarget(binding.Spec); err != nil {
+		logger.Error(err, "invalid SessionBinding spec")
+		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionSessionDiscovered, metav1.ConditionFalse, "InvalidSpec", err.Error())
+		binding.Status.Phase = v1alpha1.SessionBindingPhaseError
+		return ctrl.Result{}, nil
+	}
+
+	cf, err := r.cloudflareClientFor(ctx, binding)
+	if err != nil {
+		logger.Error(err, "failed to build Cloudflare client for binding")
+		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionSessionDiscovered, metav1.ConditionFalse, "CredentialsUnavailable", err.Error())
+		binding.Status.Phase = v1alpha1.SessionBindingPhaseError
+		return ctrl.Result{RequeueAfter: recordFailure(binding)}, nil
+	}
+
+	// Without credentials every Cloudflare call fails; say so once instead of
+	// failing (and backing off) on each reconcile. Not counted as a failure.
+	if checker, ok := cf.(credentialChecker); ok && !checker.HasCredentials() {
+		const msg = "Cloudflare credentials are not configured for the operator"
+		if cond := meta.FindStatusCondition(binding.Status.Conditions, v1alpha1.ConditionSessionDiscovered); cond == nil || cond.Reason != "MissingCredentials" {
+			r.Recorder.Event(binding, corev1.EventTypeWarning, "MissingCredentials", msg)
+		}
+		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionSessionDiscovered, metav1.ConditionUnknown, "MissingCredentials", msg)
+		binding.Status.Phase = v1alpha1.SessionBindingPhaseError
+		return ctrl.Result{RequeueAfter: missingCredentialsRequeue}, nil
+	}
+
 	// Issue #6: TTL enforcement — expire bindings that have exceeded their TTL.
 	if expired, result := r.checkTTLExpired(logger, binding); expired {
 		return result, nil
 	}
 
-	sessionExists, sessionErr := r.CFClient.EnsureSession(ctx, binding.Spec.SessionID)
+	sessionExists, sessionErr := cf.EnsureSession(ctx, binding.Spec.SessionID)
 	if sessionErr != nil {
 		logger.Error(sessionErr, "failed to verify Cloudflare session")
 		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionSessionDiscovered, metav1.ConditionUnknown, "CloudflareError", sessionErr.Error())
 		binding.Status.Phase = v1alpha1.SessionBindingPhaseError
-		return ctrl.Result{RequeueAfter: time.Minute}, nil
+		return ctrl.Result{RequeueAfter: cloudflareFailureRequeue(binding, sessionErr)}, nil
 	}
 
 	if !sessionExists {
+		if wait := r.expiryGraceRemaining(binding); wait > 0 {
+			logger.Info("Cloudflare session missing; waiting out grace period", "sessionID", binding.Spec.SessionID, "remaining", wait.String())
+			r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionSessionDiscovered, metav1.ConditionUnknown, "NotFoundGracePeriod",
+				fmt.Sprintf("Cloudflare session not found; expiring in %s if still missing", wait.Round(time.Second)))
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
 		logger.Info("Cloudflare session missing; marking binding expired", "sessionID", binding.Spec.SessionID)
 		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionSessionDiscovered, metav1.ConditionFalse, "NotFound", "Cloudflare session not found")
 		binding.Status.Phase = v1alpha1.SessionBindingPhaseExpired
 		return ctrl.Result{}, nil
 	}
+	binding.Status.SessionNotFoundSince = nil
 
 	r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionSessionDiscovered, metav1.ConditionTrue, "SessionActive", "Cloudflare session is active")
 
-	pod, err := r.ensureSessionPod(ctx, logger, binding)
+	pod, err := r.resolveTargetPod(ctx, logger, binding)
 	if err != nil {
 		binding.Status.Phase = v1alpha1.SessionBindingPhaseError
+		// Returned errors are already rate limited by controller-runtime; only count them.
+		recordFailure(binding)
 		return ctrl.Result{}, err
 	}
+	if err := r.updateReplicaCounts(ctx, binding); err != nil {
+		logger.V(1).Info("failed to count target pods", "sessionID", binding.Spec.SessionID, "error", err.Error())
+	}
+	if pod == nil {
+		r.waitForPod(binding, "NoReadyPods", "No ready pod matches targetSelector")
+		binding.Status.BoundPod = ""
+		return ctrl.Result{RequeueAfter: r.podReadyRequeueInterval()}, nil
+	}
 
 	if !isPodReady(pod) {
-		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionPodReady, metav1.ConditionFalse, "WaitingForReadiness", "Session pod not ready yet")
-		binding.Status.Phase = v1alpha1.SessionBindingPhasePending
+		r.waitForPod(binding, "WaitingForReadiness", "Session pod not ready yet")
 		binding.Status.BoundPod = pod.Name
-		binding.Status.RouteEndpoint = ""
-		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: r.podReadyRequeueInterval()}, nil
 	}
 
 	r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionPodReady, metav1.ConditionTrue, "PodReady", "Session pod ready")
 
-	endpoint := podEndpoint(pod)
+	endpoint := podEndpoint(pod, binding.Spec.TargetPort)
 	if endpoint == "" {
-		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionRouteConfigured, metav1.ConditionFalse, "PodEndpointMissing", "Pod ready but lacks PodIP/port")
+		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionRouteConfigured, metav1.ConditionFalse, "PodEndpointMissing", "Pod ready but lacks PodIP or the target port")
 		binding.Status.Phase = v1alpha1.SessionBindingPhaseError
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
 
-	if err := r.CFClient.EnsureRoute(ctx, binding.Spec.SessionID, endpoint); err != nil {
+	if err := r.ensureRoute(ctx, logger, cf, binding, endpoint); err != nil {
 		logger.Error(err, "failed to configure Cloudflare route", "sessionID", binding.Spec.SessionID, "endpoint", endpoint)
 		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionRouteConfigured, metav1.ConditionFalse, "CloudflareError", err.Error())
 		binding.Status.Phase = v1alpha1.SessionBindingPhaseError
-		return ctrl.Result{RequeueAfter: time.Minute}, nil
+		return ctrl.Result{RequeueAfter: cloudflareFailureRequeue(binding, err)}, nil
+	}
+
+	// The route now points somewhere other than the last programmed endpoint: a
+	// different pod was selected, or the session pod restarted with a new IP.
+	// RouteEndpoint survives readiness waits so the change is still visible here.
+	if previous := binding.Status.RouteEndpoint; previous != "" && previous != endpoint {
+		logger.Info("route endpoint changed", "sessionID", binding.Spec.SessionID, "from", previous, "to", endpoint)
+		r.Recorder.Event(binding, corev1.EventTypeNormal, "EndpointChanged",
+			fmt.Sprintf("Route endpoint changed from %s to %s (pod %s)", previous, endpoint, pod.Name))
 	}
 
 	binding.Status.Phase = v1alpha1.SessionBindingPhaseBound
+	binding.Status.ConsecutiveFailures = 0
 	binding.Status.BoundPod = pod.Name
 	binding.Status.RouteEndpoint = endpoint
 	r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionRouteConfigured, metav1.ConditionTrue, "RouteConfigured", "Cloudflare route configured")
 
-	// If TTL is set, requeue to check expiration.
-	if binding.Spec.TTLSeconds != nil {
-		ttl := time.Duration(*binding.Spec.TTLSeconds) * time.Second
-		elapsed := r.Clock.Now().Sub(binding.CreationTimestamp.Time)
-		remaining := ttl - elapsed
-		if remaining > 0 {
-			return ctrl.Result{RequeueAfter: remaining}, nil
+	// Requeue for the drift check, or sooner to check TTL expiration.
+	requeue := r.ResyncPeriod
+	if remaining := r.remainingTTL(binding); remaining > 0 {
+		if ttlRequeue := r.jitterTTLRequeue(remaining); requeue == 0 || ttlRequeue < requeue {
+			requeue = ttlRequeue
 		}
 	}
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// waitForPod marks the binding Pending while its target pod is not ready. The
+// WaitingForPod event is only emitted when the binding starts waiting, not on
+// every recheck. RouteEndpoint is left as the last programmed endpoint.
+func (r *SessionBindingReconciler) waitForPod(binding *v1alpha1.SessionBinding, reason, message string) {
+	if cond := meta.FindStatusCondition(binding.Status.Conditions, v1alpha1.ConditionPodReady); cond == nil || cond.Status != metav1.ConditionFalse {
+		r.Recorder.Event(binding, corev1.EventTypeNormal, "WaitingForPod", message)
+	}
+	r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionPodReady, metav1.ConditionFalse, reason, message)
+	binding.Status.Phase = v1alpha1.SessionBindingPhasePending
+}
+
+func (r *SessionBindingReconciler) podReadyRequeueInterval() time.Duration {
+	if r.PodReadyRequeueInterval > 0 {
+		return r.PodReadyRequeueInterval
+	}
+	return defaultPodReadyRequeueInterval
+}
+
+// expiryGraceRemaining records when the session was first reported missing and
+// returns how much of the grace period is left; zero means the binding should expire.
+func (r *SessionBindingReconciler) expiryGraceRemaining(binding *v1alpha1.SessionBinding) time.Duration {
+	if r.ExpiryGracePeriod <= 0 {
+		return 0
+	}
+	now := r.Clock.Now()
+	if binding.Status.SessionNotFoundSince == nil {
+		binding.Status.SessionNotFoundSince = &metav1.Time{Time: now}
+	}
+	return max(r.ExpiryGracePeriod-now.Sub(binding.Status.SessionNotFoundSince.Time), 0)
+}
+
+// setReadyCondition derives the aggregate Ready condition from the binding phase so
+// tooling that only understands Ready (kubectl wait, dashboards) can track bindings.
+func (r *SessionBindingReconciler) setReadyCondition(binding *v1alpha1.SessionBinding) {
+	if binding.Status.Phase == v1alpha1.SessionBindingPhaseBound {
+		r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionReady, metav1.ConditionTrue, "Bound",
+			fmt.Sprintf("Session routed to %s", binding.Status.RouteEndpoint))
+		return
+	}
+	reason := string(binding.Status.Phase)
+	if reason == "" {
+		reason = "Reconciling"
+	}
+	r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionReady, metav1.ConditionFalse, reason,
+		fmt.Sprintf("Session binding is %s", reason))
+}
+
+// giveUp moves a binding that keeps failing to the terminal Failed phase.
+func (r *SessionBindingReconciler) giveUp(logger logr.Logger, binding *v1alpha1.SessionBinding, lastErr error) {
+	msg := fmt.Sprintf("Gave up after %d consecutive failures; update the spec to retry", binding.Status.ConsecutiveFailures)
+	if lastErr != nil {
+		msg += ": " + lastErr.Error()
+	}
+	logger.Info("giving up on session binding", "sessionID", binding.Spec.SessionID, "failures", binding.Status.ConsecutiveFailures)
+	binding.Status.Phase = v1alpha1.SessionBindingPhaseFailed
+	r.setCondition(&binding.Status.Conditions, v1alpha1.ConditionStalled, metav1.ConditionTrue, "MaxRetriesExceeded", msg)
+	r.Recorder.Event(binding, corev1.EventTypeWarning, "ReconcileGivenUp", msg)
+}
+
+// recordFailure increments the binding's consecutive failure count and returns the
+// backoff interval to requeue after.
+func recordFailure(binding *v1alpha1.SessionBinding) time.Duration {
+	binding.Status.ConsecutiveFailures++
+	return errorRequeueInterval(binding.Status.ConsecutiveFailures)
+}
+
+// cloudflareFailureRequeue returns the requeue interval after a failed Cloudflare
+// call. An open circuit breaker means Cloudflare itself is down, so the binding
+// waits out the outage without counting toward MaxRetries.
+func cloudflareFailureRequeue(binding *v1alpha1.SessionBinding, err error) time.Duration {
+	if errors.Is(err, cloudflare.ErrCircuitOpen) {
+		return circuitOpenRequeue
+	}
+	return recordFailure(binding)
+}
+
+// errorRequeueInterval returns min(errorRequeueBase*2^failures, errorRequeueMax).
+func errorRequeueInterval(failures int32) time.Duration {
+	// Stop shifting once the cap is reached so large counts cannot overflow.
+	d := errorRequeueBase
+	for i := int32(0); i < failures && d < errorRequeueMax; i++ {
+		d *= 2
+	}
+	return min(d, errorRequeueMax)
+}
+
+// ensureRoute programs the session route with the binding's remaining TTL, skipping the KV write when the stored
+// value is already current: same endpoint and metadata, written with the current
+// RouteSchemaVersion. KV writes are rate-limited and eventually consistent, so
+// steady-state reconciles should not rewrite an unchanged value, while legacy or
+// older-schema values are migrated on the next reconcile. A failed read-back is
+// not fatal; we fall through to the write.
+func (r *SessionBindingReconciler) ensureRoute(ctx context.Context, logger logr.Logger, cf cloudflare.Client, binding *v1alpha1.SessionBinding, endpoint string) error {
+	metadata := cloudflare.RouteMetadata{TargetDeployment: binding.Spec.TargetDeployment}
+	current, found, err := cf.GetRouteValue(ctx, binding.Spec.SessionID)
+	if err != nil {
+		logger.V(1).Info("failed to read back Cloudflare route; rewriting", "sessionID", binding.Spec.SessionID, "error", err.Error())
+	} else if found && routeValueCurrent(current, endpoint, metadata) {
+		r.Recorder.Event(binding, corev1.EventTypeNormal, "RouteUnchanged",
+			fmt.Sprintf("Cloudflare route already points to %s", endpoint))
+		return nil
+	}
+	return cf.EnsureRouteWithMetadata(ctx, binding.Spec.SessionID, endpoint, r.remainingTTL(binding), metadata)
+}
+
+// routeValueCurrent reports whether a stored route matches what ensureRoute would write.
+func routeValueCurrent(v cloudflare.RouteValue, endpoint string, metadata cloudflare.RouteMetadata) bool {
+	var stored cloudflare.RouteMetadata
+	if v.Metadata != nil {
+		stored = *v.Metadata
+	}
+	return v.SchemaVersion == cloudflare.RouteSchemaVersion && v.Endpoint == endpoint && stored == metadata
+}
+
+// remainingTTL returns how long the binding has left before its TTL expires, or
+// zero if it has no TTL. Routes are written with this expiration so KV drops them
+// on its own if the operator never gets to clean up.
+func (r *SessionBindingReconciler) remainingTTL(binding *v1alpha1.SessionBinding) time.Duration {
+	ttl := r.effectiveTTL(binding)
+	if ttl == 0 {
+		return 0
+	}
+	return max(ttl-r.Clock.Now().Sub(binding.CreationTimestamp.Time), 0)
+}
+
+// jitterTTLRequeue lengthens (never shortens) the requeue-until-expiry interval
+// by up to ttlRequeueJitter so bindings created together with the same TTL are
+// not all reconciled and marked Expired at the same instant. Their KV routes
+// expire on their own through the TTL they were written with.
+func (r *SessionBindingReconciler) jitterTTLRequeue(remaining time.Duration) time.Duration {
+	jitter := rand.Float64
+	if r.Jitter != nil {
+		jitter = r.Jitter
+	}
+	return remaining + time.Duration(jitter()*ttlRequeueJitter*float64(remaining))
+}
+
+// effectiveTTL returns the binding's TTL, or zero if it never expires. An unset
+// TTLSeconds falls back to DefaultTTL; an explicit 0 opts out of expiry.
+func (r *SessionBindingReconciler) effectiveTTL(binding *v1alpha1.SessionBinding) time.Duration {
+	if binding.Spec.TTLSeconds == nil {
+		return r.DefaultTTL
+	}
+	return time.Duration(*binding.Spec.TTLSeconds) * time.Second
 }
 
 // checkTTLExpired checks if the binding has exceeded its TTL.
 // Returns (true, result) if expired and the caller should return early.
 func (r *SessionBindingReconciler) checkTTLExpired(logger logr.Logger, binding *v1alpha1.SessionBinding) (bool, ctrl.Result) {
-	if binding.Spec.TTLSeconds == nil {
+	ttl := r.effectiveTTL(binding)
+	if ttl == 0 {
 		return false, ctrl.Result{}
 	}
-	ttl := time.Duration(*binding.Spec.TTLSeconds) * time.Second
 	elapsed := r.Clock.Now().Sub(binding.CreationTimestamp.Time)
 	if elapsed <= ttl {
 		return false, ctrl.Result{}
@@ -192,8 +507,66 @@ func (r *SessionBindingReconciler) checkTTLExpired(logger logr.Logger, binding *
 	return true, ctrl.Result{}
 }
 
+// validateTarget checks that exactly one pod targeting mode is configured.
+func validateTarget(spec v1alpha1.SessionBindingSpec) error {
+	hasDeployment := spec.TargetDeployment != ""
+	hasSelector := spec.TargetSelector != nil
+	switch {
+	case hasDeployment && hasSelector:
+		return fmt.Errorf("targetDeployment and targetSelector are mutually exclusive")
+	case !hasDeployment && !hasSelector:
+		return fmt.Errorf("one of targetDeployment or targetSelector is required")
+	case hasSelector && len(spec.TargetSelector.MatchLabels) == 0 && len(spec.TargetSelector.MatchExpressions) == 0:
+		return fmt.Errorf("targetSelector must not be empty")
+	}
+	return nil
+}
+
+// resolveTargetPod returns the pod the session should be routed to: a dedicated
+// pod cloned from TargetDeployment, or an existing ready pod matching
+// TargetSelector chosen by session affinity. A nil pod with no error means no selector pod is ready yet.
+func (r *SessionBindingReconciler) resolveTargetPod(ctx context.Context, logger logr.Logger, binding *v1alpha1.SessionBinding) (*corev1.Pod, error) {
+	if binding.Spec.TargetSelector == nil {
+		return r.ensureSessionPod(ctx, logger, binding)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(binding.Spec.TargetSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing targetSelector: %w", err)
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(binding.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("listing pods for targetSelector: %w", err)
+	}
+	var ready []corev1.Pod
+	for i := range pods.Items {
+		if isPodReady(&pods.Items[i]) {
+			// Stay on the current pod while it is ready so sessions stay sticky
+			// even when other pods come and go.
+			if pods.Items[i].Name == binding.Status.BoundPod {
+				return &pods.Items[i], nil
+			}
+			ready = append(ready, pods.Items[i])
+		}
+	}
+	return selectPodForSession(binding.Spec.SessionID, ready), nil
+}
+
+// selectPodForSession deterministically maps a session to one of the ready pods by
+// hashing the session ID over the pods sorted by name, so every reconcile with the
+// same pod set picks the same backend. Returns nil if there are no pods.
+func selectPodForSession(sessionID string, ready []corev1.Pod) *corev1.Pod {
+	if len(ready) == 0 {
+		return nil
+	}
+	sort.Slice(ready, func(i, j int) bool { return ready[i].Name < ready[j].Name })
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(sessionID))
+	return &ready[h.Sum32()%uint32(len(ready))]
+}
+
 func (r *SessionBindingReconciler) ensureSessionPod(ctx context.Context, logger logr.Logger, binding *v1alpha1.SessionBinding) (*corev1.Pod, error) {
-	podName := fmt.Sprintf("session-%s", binding.Spec.SessionID)
+	podName := names.SessionPodName(binding.Spec.SessionID)
 	pod := &corev1.Pod{}
 	if err := r.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: podName}, pod); err == nil {
 		return pod, nil
@@ -241,6 +614,42 @@ func (r *SessionBindingReconciler) ensureSessionPod(ctx context.Context, logger
 	return pod, nil
 }
 
+// updateReplicaCounts records how many pods back the binding's target, and how
+// many of them are ready, so "bound but no healthy backend" shows up in status.
+// Pods are matched by TargetSelector or the target deployment's selector; session
+// pods cloned for other bindings are not counted.
+func (r *SessionBindingReconciler) updateReplicaCounts(ctx context.Context, binding *v1alpha1.SessionBinding) error {
+	labelSelector := binding.Spec.TargetSelector
+	if labelSelector == nil {
+		deployment := &appsv1.Deployment{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.Spec.TargetDeployment}, deployment); err != nil {
+			return fmt.Errorf("fetching target deployment %q: %w", binding.Spec.TargetDeployment, err)
+		}
+		labelSelector = deployment.Spec.Selector
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return fmt.Errorf("parsing target selector: %w", err)
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(binding.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return fmt.Errorf("listing target pods: %w", err)
+	}
+	var ready, total int32
+	for i := range pods.Items {
+		if session := pods.Items[i].Labels[podSessionLabelKey]; session != "" && session != binding.Spec.SessionID {
+			continue
+		}
+		total++
+		if isPodReady(&pods.Items[i]) {
+			ready++
+		}
+	}
+	binding.Status.ReadyReplicas = ready
+	binding.Status.TotalReplicas = total
+	return nil
+}
+
 func isPodReady(pod *corev1.Pod) bool {
 	if pod.Status.Phase != corev1.PodRunning {
 		return false
@@ -253,18 +662,44 @@ func isPodReady(pod *corev1.Pod) bool {
 	return false
 }
 
-func podEndpoint(pod *corev1.Pod) string {
+// podEndpoint returns the pod's IP:port. A named targetPort is resolved against
+// the containers' declared ports and yields "" if no container declares it; a
+// numeric one is used as-is. Without a targetPort the first declared port is used,
+// falling back to 80.
+func podEndpoint(pod *corev1.Pod, targetPort *intstr.IntOrString) string {
 	if pod.Status.PodIP == "" {
 		return ""
 	}
 	port := int32(80)
-	for _, container := range pod.Spec.Containers {
-		if len(container.Ports) > 0 {
-			port = container.Ports[0].ContainerPort
-			break
+	switch {
+	case targetPort != nil && targetPort.Type == intstr.Int:
+		port = targetPort.IntVal
+	case targetPort != nil && targetPort.Type == intstr.String:
+		port = 0
+		for _, container := range pod.Spec.Containers {
+			for _, p := range container.Ports {
+				if p.Name == targetPort.StrVal {
+					port = p.ContainerPort
+					break
+				}
+			}
+			if port != 0 {
+				break
+			}
+		}
+		if port == 0 {
+			return ""
+		}
+	default:
+		for _, container := range pod.Spec.Containers {
+			if len(container.Ports) > 0 {
+				port = container.Ports[0].ContainerPort
+				break
+			}
 		}
 	}
-	return fmt.Sprintf("%s:%d", pod.Status.PodIP, port)
+	// JoinHostPort brackets IPv6 addresses, e.g. [fd00::1]:8080.
+	return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port)))
 }
 
 func (r *SessionBindingReconciler) handleDeletion(ctx context.Context, logger logr.Logger, binding *v1alpha1.SessionBinding) (ctrl.Result, error) {
@@ -272,7 +707,9 @@ func (r *SessionBindingReconciler) handleDeletion(ctx context.Context, logger lo
 		return ctrl.Result{}, nil
 	}
 
+	// Keep the finalizer until cleanup succeeds; the returned error requeues with backoff.
 	if err := r.cleanupResources(ctx, logger, binding); err != nil {
+		r.Recorder.Event(binding, corev1.EventTypeWarning, "CleanupFailed", err.Error())
 		return ctrl.Result{}, err
 	}
 
@@ -286,7 +723,8 @@ func (r *SessionBindingReconciler) handleDeletion(ctx context.Context, logger lo
 func (r *SessionBindingReconciler) cleanupResources(ctx context.Context, logger logr.Logger, binding *v1alpha1.SessionBinding) error {
 	if binding.Status.BoundPod != "" {
 		pod := &corev1.Pod{}
-		if err := r.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.Status.BoundPod}, pod); err == nil {
+		// Only delete pods we created; selector-targeted pods belong to the user.
+		if err := r.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.Status.BoundPod}, pod); err == nil && metav1.IsControlledBy(pod, binding) {
 			if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
 				return fmt.Errorf("deleting session pod %q: %w", binding.Status.BoundPod, err)
 			}
@@ -294,8 +732,20 @@ func (r *SessionBindingReconciler) cleanupResources(ctx context.Context, logger
 	}
 
 	if binding.Spec.SessionID != "" {
-		if err := r.CFClient.DeleteRoute(ctx, binding.Spec.SessionID); err != nil {
-			return fmt.Errorf("deleting cloudflare route for session %q: %w", binding.Spec.SessionID, err)
+		cf, err := r.cloudflareClientFor(ctx, binding)
+		switch {
+		case apierrors.IsNotFound(err):
+			// The tenant's credentials are gone, so the route cannot be removed; don't
+			// block deletion on it. Routes written with a TTL still expire in KV.
+			logger.Info("credentials secret missing; skipping Cloudflare route cleanup", "sessionID", binding.Spec.SessionID)
+			r.Recorder.Event(binding, corev1.EventTypeWarning, "RouteCleanupSkipped",
+				fmt.Sprintf("Credentials secret not found; Cloudflare route for session %s was not removed", binding.Spec.SessionID))
+		case err != nil:
+			return err
+		default:
+			if err := cf.DeleteRoute(ctx, binding.Spec.SessionID); err != nil {
+				return fmt.Errorf("deleting cloudflare route for session %q: %w", binding.Spec.SessionID, err)
+			}
 		}
 	}
 
@@ -317,10 +767,52 @@ func (r *SessionBindingReconciler) patchStatus(ctx context.Context, binding *v1a
 	return r.Status().Update(ctx, current)
 }
 
+// bindingsForPod maps a pod event to the bindings in its namespace that are bound
+// to it or whose targetSelector matches it, so readiness changes trigger a reroute
+// without waiting for a requeue.
+func (r *SessionBindingReconciler) bindingsForPod(ctx context.Context, obj client.Object) []reconcile.Request {
+	bindings := &v1alpha1.SessionBindingList{}
+	if err := r.List(ctx, bindings, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "listing SessionBindings for pod event", "pod", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range bindings.Items {
+		b := &bindings.Items[i]
+		matches := b.Status.BoundPod == obj.GetName()
+		if !matches && b.Spec.TargetSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(b.Spec.TargetSelector)
+			matches = err == nil && selector.Matches(labels.Set(obj.GetLabels()))
+		}
+		if matches {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(b)})
+		}
+	}
+	return requests
+}
+
+// bindingsForDeployment maps a deployment event to the bindings that target it.
+func (r *SessionBindingReconciler) bindingsForDeployment(ctx context.Context, obj client.Object) []reconcile.Request {
+	bindings := &v1alpha1.SessionBindingList{}
+	if err := r.List(ctx, bindings, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "listing SessionBindings for deployment event", "deployment", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range bindings.Items {
+		if bindings.Items[i].Spec.TargetDeployment == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&bindings.Items[i])})
+		}
+	}
+	return requests
+}
+
 func (r *SessionBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.SessionBinding{}).
 		Owns(&corev1.Pod{}).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.bindingsForPod)).
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.bindingsForDeployment)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
 		Complete(r)
 }
@@ -3,18 +3,31 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Creme-ala-creme/cloudflare-session-operator/api/v1alpha1"
+	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/cloudflare"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // fakeClock is a controllable clock for testing.
@@ -35,17 +48,43 @@ func (r *fakeRecorder) Event(object runtime.Object, eventtype, reason, message s
 
 // fakeCFClient is a mock Cloudflare client.
 type fakeCFClient struct {
-	sessionExists bool
-	sessionErr    error
-	routeErr      error
-	deleteErr     error
+	sessionExists     bool
+	sessionErr        error
+	routeErr          error
+	deleteErr         error
+	routes            []cloudflare.RouteEntry
+	listErr           error
+	storedRoutes      map[string]cloudflare.RouteValue
+	getErr            error
+	ensureCalls       int
+	sessionCalls      int
+	lastRouteTTL      time.Duration
+	lastRouteMetadata cloudflare.RouteMetadata
+	// hang makes EnsureSession block until its context is done.
+	hang bool
 }
 
-func (c *fakeCFClient) EnsureSession(_ context.Context, _ string) (bool, error) {
+func (c *fakeCFClient) EnsureSession(ctx context.Context, _ string) (bool, error) {
+	c.sessionCalls++
+	if c.hang {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
 	return c.sessionExists, c.sessionErr
 }
 
-func (c *fakeCFClient) EnsureRoute(_ context.Context, _, _ string) error {
+func (c *fakeCFClient) EnsureRoute(ctx context.Context, sessionID, endpoint string) error {
+	return c.EnsureRouteWithTTL(ctx, sessionID, endpoint, 0)
+}
+
+func (c *fakeCFClient) EnsureRouteWithTTL(ctx context.Context, sessionID, endpoint string, ttl time.Duration) error {
+	return c.EnsureRouteWithMetadata(ctx, sessionID, endpoint, ttl, cloudflare.RouteMetadata{})
+}
+
+func (c *fakeCFClient) EnsureRouteWithMetadata(_ context.Context, _, _ string, ttl time.Duration, metadata cloudflare.RouteMetadata) error {
+	c.ensureCalls++
+	c.lastRouteTTL = ttl
+	c.lastRouteMetadata = metadata
 	return c.routeErr
 }
 
@@ -53,6 +92,28 @@ func (c *fakeCFClient) DeleteRoute(_ context.Context, _ string) error {
 	return c.deleteErr
 }
 
+func (c *fakeCFClient) DeleteRoutes(_ context.Context, _ []string) error {
+	return c.deleteErr
+}
+
+func (c *fakeCFClient) GetRoute(ctx context.Context, sessionID string) (string, bool, error) {
+	value, ok, err := c.GetRouteValue(ctx, sessionID)
+	return value.Endpoint, ok, err
+}
+
+func (c *fakeCFClient) GetRouteValue(_ context.Context, sessionID string) (cloudflare.RouteValue, bool, error) {
+	value, ok := c.storedRoutes[sessionID]
+	return value, ok, c.getErr
+}
+
+func (c *fakeCFClient) Ping(_ context.Context) error {
+	return nil
+}
+
+func (c *fakeCFClient) ListRoutes(_ context.Context) ([]cloudflare.RouteEntry, error) {
+	return c.routes, c.listErr
+}
+
 func newTestScheme() *runtime.Scheme {
 	s := runtime.NewScheme()
 	_ = clientgoscheme.AddToScheme(s)
@@ -102,9 +163,19 @@ func TestReconcileActive_ValidSession_PodCreated(t *testing.T) {
 		},
 	}
 
+	// A pod cloned for another session matches the deployment selector but
+	// does not back this binding.
+	otherSessionPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session-other",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "my-app", podSessionLabelKey: "other"},
+		},
+	}
+
 	client := fake.NewClientBuilder().
 		WithScheme(scheme).
-		WithObjects(binding, deployment).
+		WithObjects(binding, deployment, otherSessionPod).
 		WithStatusSubresource(binding).
 		Build()
 
@@ -117,12 +188,16 @@ func TestReconcileActive_ValidSession_PodCreated(t *testing.T) {
 		Clock:    &fakeClock{now: now},
 	}
 
-	_, err := r.Reconcile(context.Background(), ctrl.Request{
+	result, err := r.Reconcile(context.Background(), ctrl.Request{
 		NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"},
 	})
 	if err != nil {
 		t.Fatalf("Reconcile() error = %v", err)
 	}
+	// The new pod is not ready yet; wait at the fixed readiness cadence.
+	if result.RequeueAfter != defaultPodReadyRequeueInterval {
+		t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, defaultPodReadyRequeueInterval)
+	}
 
 	// Verify pod was created
 	pod := &corev1.Pod{}
@@ -137,10 +212,25 @@ func TestReconcileActive_ValidSession_PodCreated(t *testing.T) {
 		t.Errorf("pod label %q = %q, want %q", podSessionLabelKey, pod.Labels[podSessionLabelKey], "valid-session-1")
 	}
 
+	updated := &v1alpha1.SessionBinding{}
+	if err := client.Get(context.Background(), types.NamespacedName{Name: "test-binding", Namespace: "default"}, updated); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if updated.Status.ReadyReplicas != 0 || updated.Status.TotalReplicas != 1 {
+		t.Errorf("replicas = %d/%d ready, want 0/1", updated.Status.ReadyReplicas, updated.Status.TotalReplicas)
+	}
+
 	// Verify event was emitted
 	if len(rec.events) == 0 {
 		t.Error("expected at least one event to be recorded")
 	}
+	waiting := false
+	for _, e := range rec.events {
+		waiting = waiting || strings.Contains(e, "WaitingForPod")
+	}
+	if !waiting {
+		t.Errorf("expected WaitingForPod event, got %v", rec.events)
+	}
 }
 
 func TestReconcileActive_SessionNotFound_Expired(t *testing.T) {
@@ -188,6 +278,343 @@ func TestReconcileActive_SessionNotFound_Expired(t *testing.T) {
 	}
 }
 
+func TestReconcileActive_SessionNotFound_GracePeriod(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "flaky-session",
+			TargetDeployment: "my-app",
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding).
+		WithStatusSubresource(binding).
+		Build()
+
+	clock := &fakeClock{now: now}
+	cf := &fakeCFClient{sessionExists: false}
+	r := &SessionBindingReconciler{
+		Client:            client,
+		Scheme:            scheme,
+		CFClient:          cf,
+		Recorder:          &fakeRecorder{},
+		Clock:             clock,
+		ExpiryGracePeriod: time.Minute,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	get := func() *v1alpha1.SessionBinding {
+		updated := &v1alpha1.SessionBinding{}
+		if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+			t.Fatalf("get binding: %v", err)
+		}
+		return updated
+	}
+
+	// First not-found: record the timestamp and requeue for the full grace period.
+	result, err := r.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != time.Minute {
+		t.Errorf("RequeueAfter = %v, want 1m", result.RequeueAfter)
+	}
+	updated := get()
+	if updated.Status.Phase == v1alpha1.SessionBindingPhaseExpired {
+		t.Fatal("binding expired before the grace period elapsed")
+	}
+	if updated.Status.SessionNotFoundSince == nil || !updated.Status.SessionNotFoundSince.Time.Equal(now) {
+		t.Errorf("SessionNotFoundSince = %v, want %v", updated.Status.SessionNotFoundSince, now)
+	}
+
+	// Still missing after the grace period: expire.
+	clock.now = now.Add(2 * time.Minute)
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if phase := get().Status.Phase; phase != v1alpha1.SessionBindingPhaseExpired {
+		t.Errorf("phase = %q after grace period, want Expired", phase)
+	}
+}
+
+func TestReconcileActive_SessionFoundClearsGracePeriod(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now)},
+		Spec:       v1alpha1.SessionBindingSpec{SessionID: "flaky-session", TargetDeployment: "my-app"},
+		Status:     v1alpha1.SessionBindingStatus{SessionNotFoundSince: &metav1.Time{Time: now}},
+	}
+	scheme := newTestScheme()
+	r := &SessionBindingReconciler{
+		Client:            fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme:            scheme,
+		CFClient:          &fakeCFClient{sessionExists: true},
+		Recorder:          &fakeRecorder{},
+		Clock:             &fakeClock{now: now.Add(10 * time.Second)},
+		ExpiryGracePeriod: time.Minute,
+	}
+	// The missing deployment makes this reconcile fail later; only the session check matters here.
+	_, _ = r.reconcileActive(context.Background(), logr.Discard(), binding)
+	if binding.Status.SessionNotFoundSince != nil {
+		t.Errorf("SessionNotFoundSince = %v, want cleared once the session is found", binding.Status.SessionNotFoundSince)
+	}
+}
+
+func TestReconcileActive_MissingCredentials(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "some-session",
+			TargetDeployment: "my-app",
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding).
+		WithStatusSubresource(binding).
+		Build()
+
+	rec := &fakeRecorder{}
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: &cloudflare.APIClient{},
+		Recorder: rec,
+		Clock:    &fakeClock{now: now},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+
+	for i := 0; i < 2; i++ {
+		result, err := r.Reconcile(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if result.RequeueAfter != missingCredentialsRequeue {
+			t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, missingCredentialsRequeue)
+		}
+	}
+
+	updated := &v1alpha1.SessionBinding{}
+	if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if updated.Status.Phase != v1alpha1.SessionBindingPhaseError {
+		t.Errorf("phase = %q, want Error", updated.Status.Phase)
+	}
+	cond := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionSessionDiscovered)
+	if cond == nil || cond.Reason != "MissingCredentials" {
+		t.Errorf("SessionDiscovered condition = %+v, want reason MissingCredentials", cond)
+	}
+	if updated.Status.ConsecutiveFailures != 0 {
+		t.Errorf("ConsecutiveFailures = %d, want 0", updated.Status.ConsecutiveFailures)
+	}
+
+	warnings := 0
+	for _, e := range rec.events {
+		if strings.Contains(e, "MissingCredentials") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("MissingCredentials events = %d, want 1 (%v)", warnings, rec.events)
+	}
+}
+
+func TestReconcile_Timeout(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "hung-session",
+			TargetDeployment: "my-app",
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding).
+		WithStatusSubresource(binding).
+		Build()
+
+	rec := &fakeRecorder{}
+	r := &SessionBindingReconciler{
+		Client:           client,
+		Scheme:           scheme,
+		CFClient:         &fakeCFClient{hang: true},
+		Recorder:         rec,
+		Clock:            &fakeClock{now: now},
+		ReconcileTimeout: 10 * time.Millisecond,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+
+	result, err := r.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if want := errorRequeueInterval(1); result.RequeueAfter != want {
+		t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, want)
+	}
+
+	updated := &v1alpha1.SessionBinding{}
+	if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if updated.Status.ConsecutiveFailures != 1 {
+		t.Errorf("ConsecutiveFailures = %d, want 1", updated.Status.ConsecutiveFailures)
+	}
+	found := false
+	for _, e := range rec.events {
+		found = found || strings.Contains(e, "ReconcileTimeout")
+	}
+	if !found {
+		t.Errorf("expected ReconcileTimeout event, got %v", rec.events)
+	}
+}
+
+func TestCloudflareClientFor(t *testing.T) {
+	scheme := newTestScheme()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "default"},
+		Data: map[string][]byte{
+			"account_id":      []byte("acct-a"),
+			"api_token":       []byte("token-a"),
+			"kv_namespace_id": []byte("ns-a"),
+			"api_base_url":    []byte("https://cf.tenant-a.example/client/v4"),
+		},
+	}
+	incomplete := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-b", Namespace: "default"},
+		Data:       map[string][]byte{"account_id": []byte("acct-b")},
+	}
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, incomplete).Build()
+
+	operatorClient := &fakeCFClient{}
+	var built []CloudflareCredentials
+	r := &SessionBindingReconciler{
+		Client:   k8s,
+		Scheme:   scheme,
+		CFClient: operatorClient,
+		NewCFClient: func(creds CloudflareCredentials) cloudflare.Client {
+			built = append(built, creds)
+			return &fakeCFClient{}
+		},
+	}
+	withRef := func(name string) *v1alpha1.SessionBinding {
+		b := &v1alpha1.SessionBinding{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default"}}
+		if name != "" {
+			b.Spec.CredentialsSecretRef = &corev1.LocalObjectReference{Name: name}
+		}
+		return b
+	}
+	ctx := context.Background()
+
+	if cf, err := r.cloudflareClientFor(ctx, withRef("")); err != nil || cf != operatorClient {
+		t.Fatalf("no secret ref: got %v, %v; want the operator client", cf, err)
+	}
+
+	first, err := r.cloudflareClientFor(ctx, withRef("tenant-a"))
+	if err != nil {
+		t.Fatalf("tenant-a: %v", err)
+	}
+	second, err := r.cloudflareClientFor(ctx, withRef("tenant-a"))
+	if err != nil {
+		t.Fatalf("tenant-a again: %v", err)
+	}
+	if first != second || len(built) != 1 {
+		t.Errorf("expected the tenant client to be cached, built %d clients", len(built))
+	}
+	want := CloudflareCredentials{AccountID: "acct-a", APIToken: "token-a", KVNamespace: "ns-a", BaseURL: "https://cf.tenant-a.example/client/v4"}
+	if built[0] != want {
+		t.Errorf("credentials = %+v, want %+v", built[0], want)
+	}
+
+	// A rotated secret gets a fresh client.
+	secret.Data["api_token"] = []byte("token-a2")
+	if err := k8s.Update(ctx, secret); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+	if _, err := r.cloudflareClientFor(ctx, withRef("tenant-a")); err != nil {
+		t.Fatalf("tenant-a after rotation: %v", err)
+	}
+	if len(built) != 2 || built[1].APIToken != "token-a2" {
+		t.Errorf("expected a new client after rotation, built %+v", built)
+	}
+
+	if _, err := r.cloudflareClientFor(ctx, withRef("tenant-b")); err == nil || !strings.Contains(err.Error(), "api_token, kv_namespace_id") {
+		t.Errorf("incomplete secret error = %v, want missing api_token, kv_namespace_id", err)
+	}
+	if _, err := r.cloudflareClientFor(ctx, withRef("missing")); !apierrors.IsNotFound(err) {
+		t.Errorf("missing secret error = %v, want NotFound", err)
+	}
+}
+
+func TestHandleDeletion_MissingCredentialsSecretDoesNotBlock(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			Finalizers:        []string{sessionBindingFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: now},
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:            "tenant-session",
+			TargetDeployment:     "my-app",
+			CredentialsSecretRef: &corev1.LocalObjectReference{Name: "gone"},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(binding).Build()
+
+	rec := &fakeRecorder{}
+	r := &SessionBindingReconciler{
+		Client:      client,
+		Scheme:      scheme,
+		CFClient:    &fakeCFClient{},
+		NewCFClient: func(CloudflareCredentials) cloudflare.Client { return &fakeCFClient{} },
+		Recorder:    rec,
+		Clock:       &fakeClock{now: now},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	// Removing the last finalizer lets the fake client delete the object.
+	if err := client.Get(context.Background(), req.NamespacedName, &v1alpha1.SessionBinding{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected binding to be deleted, got err = %v", err)
+	}
+	found := false
+	for _, e := range rec.events {
+		found = found || strings.Contains(e, "RouteCleanupSkipped")
+	}
+	if !found {
+		t.Errorf("expected RouteCleanupSkipped event, got %v", rec.events)
+	}
+}
+
 func TestReconcileActive_TTLExpired(t *testing.T) {
 	scheme := newTestScheme()
 	creationTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -320,6 +747,32 @@ func TestReconcileActive_TTLNotExpired(t *testing.T) {
 	}
 }
 
+func TestJitterTTLRequeueLengthenOnly(t *testing.T) {
+	const remaining = 10 * time.Minute
+	tests := []struct {
+		jitter float64
+		want   time.Duration
+	}{
+		{0, remaining},
+		{0.5, 10*time.Minute + 30*time.Second},
+		{0.75, 10*time.Minute + 45*time.Second},
+	}
+	for _, tt := range tests {
+		r := &SessionBindingReconciler{Jitter: func() float64 { return tt.jitter }}
+		if got := r.jitterTTLRequeue(remaining); got != tt.want {
+			t.Errorf("jitterTTLRequeue(%v) with jitter %v = %v, want %v", remaining, tt.jitter, got, tt.want)
+		}
+	}
+
+	// The default source never requeues before expiry and stays within +10%.
+	r := &SessionBindingReconciler{}
+	for i := 0; i < 100; i++ {
+		if got := r.jitterTTLRequeue(remaining); got < remaining || got >= 11*time.Minute {
+			t.Fatalf("jitterTTLRequeue(%v) = %v, outside [10m, 11m)", remaining, got)
+		}
+	}
+}
+
 func TestReconcileActive_InvalidSessionID(t *testing.T) {
 	scheme := newTestScheme()
 	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -421,53 +874,101 @@ func TestReconcileActive_CloudflareError(t *testing.T) {
 	}
 }
 
-func TestHandleDeletion_CleansUpResources(t *testing.T) {
+func TestReconcileActive_CircuitOpenRequeuesWithoutCountingFailure(t *testing.T) {
 	scheme := newTestScheme()
 	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
-	deletionTime := metav1.NewTime(now)
 
 	binding := &v1alpha1.SessionBinding{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:              "test-binding",
 			Namespace:         "default",
-			DeletionTimestamp: &deletionTime,
-			Finalizers:        []string{sessionBindingFinalizer},
-			CreationTimestamp: metav1.NewTime(now.Add(-1 * time.Hour)),
+			CreationTimestamp: metav1.NewTime(now),
 		},
 		Spec: v1alpha1.SessionBindingSpec{
-			SessionID:        "cleanup-session",
+			SessionID:        "outage-session",
 			TargetDeployment: "my-app",
 		},
-		Status: v1alpha1.SessionBindingStatus{
-			BoundPod: "session-cleanup-session",
-		},
-	}
-
-	pod := &corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "session-cleanup-session",
-			Namespace: "default",
-		},
 	}
 
 	client := fake.NewClientBuilder().
 		WithScheme(scheme).
-		WithObjects(binding, pod).
+		WithObjects(binding).
 		WithStatusSubresource(binding).
 		Build()
 
-	rec := &fakeRecorder{}
 	r := &SessionBindingReconciler{
 		Client:   client,
 		Scheme:   scheme,
-		CFClient: &fakeCFClient{},
-		Recorder: rec,
+		CFClient: &fakeCFClient{sessionErr: fmt.Errorf("executing session check request: %w", cloudflare.ErrCircuitOpen)},
+		Recorder: &fakeRecorder{},
 		Clock:    &fakeClock{now: now},
 	}
 
-	_, err := r.Reconcile(context.Background(), ctrl.Request{
-		NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"},
-	})
+	key := types.NamespacedName{Name: "test-binding", Namespace: "default"}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != circuitOpenRequeue {
+		t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, circuitOpenRequeue)
+	}
+
+	var updated v1alpha1.SessionBinding
+	if err := client.Get(context.Background(), key, &updated); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if updated.Status.ConsecutiveFailures != 0 {
+		t.Errorf("ConsecutiveFailures = %d, want 0", updated.Status.ConsecutiveFailures)
+	}
+}
+
+func TestHandleDeletion_CleansUpResources(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deletionTime := metav1.NewTime(now)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			DeletionTimestamp: &deletionTime,
+			Finalizers:        []string{sessionBindingFinalizer},
+			CreationTimestamp: metav1.NewTime(now.Add(-1 * time.Hour)),
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "cleanup-session",
+			TargetDeployment: "my-app",
+		},
+		Status: v1alpha1.SessionBindingStatus{
+			BoundPod: "session-cleanup-session",
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session-cleanup-session",
+			Namespace: "default",
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding, pod).
+		WithStatusSubresource(binding).
+		Build()
+
+	rec := &fakeRecorder{}
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: &fakeCFClient{},
+		Recorder: rec,
+		Clock:    &fakeClock{now: now},
+	}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"},
+	})
 	if err != nil {
 		t.Fatalf("Reconcile() error = %v", err)
 	}
@@ -475,13 +976,1070 @@ func TestHandleDeletion_CleansUpResources(t *testing.T) {
 	// Verify cleanup event was emitted
 	found := false
 	for _, e := range rec.events {
-		if e == "Normal CleanedUp Removed Cloudflare route and session pod" {
+		if e == "Normal CleanedUp Removed Cloudflare route and session pod" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("expected CleanedUp event, got events: %v", rec.events)
+	}
+}
+
+// storedRoute returns a route value as the current operator version writes it.
+func storedRoute(endpoint, targetDeployment string) cloudflare.RouteValue {
+	return cloudflare.RouteValue{
+		SchemaVersion: cloudflare.RouteSchemaVersion,
+		Endpoint:      endpoint,
+		Metadata:      &cloudflare.RouteMetadata{TargetDeployment: targetDeployment},
+	}
+}
+
+func TestReconcileActive_RouteWriteSkippedWhenUnchanged(t *testing.T) {
+	tests := []struct {
+		name            string
+		stored          map[string]cloudflare.RouteValue
+		wantEnsureCalls int
+		wantEvent       bool
+	}{
+		{"unchanged endpoint", map[string]cloudflare.RouteValue{"steady-session": storedRoute("10.0.0.5:8080", "my-app")}, 0, true},
+		{"changed endpoint", map[string]cloudflare.RouteValue{"steady-session": storedRoute("10.0.0.9:8080", "my-app")}, 1, false},
+		{"missing route", nil, 1, false},
+		{"legacy plain value", map[string]cloudflare.RouteValue{"steady-session": {Endpoint: "10.0.0.5:8080"}}, 1, false},
+		{"older schema version", map[string]cloudflare.RouteValue{"steady-session": func() cloudflare.RouteValue {
+			v := storedRoute("10.0.0.5:8080", "my-app")
+			v.SchemaVersion = cloudflare.RouteSchemaVersion - 1
+			return v
+		}()}, 1, false},
+		{"stale metadata", map[string]cloudflare.RouteValue{"steady-session": storedRoute("10.0.0.5:8080", "old-app")}, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := newTestScheme()
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			binding := &v1alpha1.SessionBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-binding",
+					Namespace:         "default",
+					CreationTimestamp: metav1.NewTime(now),
+				},
+				Spec: v1alpha1.SessionBindingSpec{
+					SessionID:        "steady-session",
+					TargetDeployment: "my-app",
+				},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "session-steady-session",
+					Namespace: "default",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "app",
+						Image: "my-app:latest",
+						Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+					}},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					PodIP: "10.0.0.5",
+					Conditions: []corev1.PodCondition{
+						{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+					},
+				},
+			}
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(binding, pod).
+				WithStatusSubresource(binding).
+				Build()
+
+			cf := &fakeCFClient{sessionExists: true, storedRoutes: tt.stored}
+			rec := &fakeRecorder{}
+			r := &SessionBindingReconciler{
+				Client:   client,
+				Scheme:   scheme,
+				CFClient: cf,
+				Recorder: rec,
+				Clock:    &fakeClock{now: now},
+			}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"},
+			})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if cf.ensureCalls != tt.wantEnsureCalls {
+				t.Errorf("EnsureRoute calls = %d, want %d", cf.ensureCalls, tt.wantEnsureCalls)
+			}
+			if tt.wantEnsureCalls > 0 && cf.lastRouteMetadata.TargetDeployment != "my-app" {
+				t.Errorf("route metadata = %+v, want targetDeployment my-app", cf.lastRouteMetadata)
+			}
+			gotEvent := false
+			for _, e := range rec.events {
+				if strings.Contains(e, "RouteUnchanged") {
+					gotEvent = true
+				}
+			}
+			if gotEvent != tt.wantEvent {
+				t.Errorf("RouteUnchanged event = %v, want %v (events: %v)", gotEvent, tt.wantEvent, rec.events)
+			}
+
+			updated := &v1alpha1.SessionBinding{}
+			if err := client.Get(context.Background(), types.NamespacedName{Name: "test-binding", Namespace: "default"}, updated); err != nil {
+				t.Fatalf("get binding: %v", err)
+			}
+			if updated.Status.Phase != v1alpha1.SessionBindingPhaseBound {
+				t.Errorf("phase = %q, want %q", updated.Status.Phase, v1alpha1.SessionBindingPhaseBound)
+			}
+			ready := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionReady)
+			if ready == nil || ready.Status != metav1.ConditionTrue || ready.LastTransitionTime.IsZero() {
+				t.Errorf("expected Ready=True with a transition time, got %+v", ready)
+			}
+		})
+	}
+}
+
+func TestErrorRequeueInterval(t *testing.T) {
+	tests := []struct {
+		failures int32
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, errorRequeueMax},
+		{1000, errorRequeueMax},
+	}
+	for _, tt := range tests {
+		if got := errorRequeueInterval(tt.failures); got != tt.want {
+			t.Errorf("errorRequeueInterval(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestReconcileActive_ConsecutiveFailuresBackOffAndReset(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "flaky-session",
+			TargetDeployment: "my-app",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-flaky-session", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "my-app:latest"}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "10.0.0.7",
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding, pod).
+		WithStatusSubresource(binding).
+		Build()
+
+	cf := &fakeCFClient{sessionErr: fmt.Errorf("cloudflare API timeout")}
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: cf,
+		Recorder: &fakeRecorder{},
+		Clock:    &fakeClock{now: now},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	get := func() *v1alpha1.SessionBinding {
+		updated := &v1alpha1.SessionBinding{}
+		if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+			t.Fatalf("get binding: %v", err)
+		}
+		return updated
+	}
+
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		result, err := r.Reconcile(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if result.RequeueAfter != want {
+			t.Errorf("failure %d: RequeueAfter = %v, want %v", i+1, result.RequeueAfter, want)
+		}
+		if got := get().Status.ConsecutiveFailures; got != int32(i+1) {
+			t.Errorf("failure %d: ConsecutiveFailures = %d", i+1, got)
+		}
+	}
+
+	cf.sessionErr = nil
+	cf.sessionExists = true
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	updated := get()
+	if updated.Status.Phase != v1alpha1.SessionBindingPhaseBound {
+		t.Fatalf("phase = %q, want Bound", updated.Status.Phase)
+	}
+	if updated.Status.ConsecutiveFailures != 0 {
+		t.Errorf("ConsecutiveFailures = %d after success, want 0", updated.Status.ConsecutiveFailures)
+	}
+}
+
+func TestReconcileActive_ResyncRequeue(t *testing.T) {
+	ttl := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name         string
+		ttlSeconds   *int64
+		resyncPeriod time.Duration
+		want         time.Duration
+	}{
+		{"no resync, no TTL", nil, 0, 0},
+		{"resync without TTL", nil, 30 * time.Minute, 30 * time.Minute},
+		{"TTL expires before resync", ttl(600), 30 * time.Minute, 10 * time.Minute},
+		{"resync before TTL expiry", ttl(7200), 30 * time.Minute, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := newTestScheme()
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			binding := &v1alpha1.SessionBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-binding",
+					Namespace:         "default",
+					CreationTimestamp: metav1.NewTime(now),
+					Finalizers:        []string{sessionBindingFinalizer},
+				},
+				Spec: v1alpha1.SessionBindingSpec{
+					SessionID:        "resync-session",
+					TargetDeployment: "my-app",
+					TTLSeconds:       tt.ttlSeconds,
+				},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "session-resync-session", Namespace: "default"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "app",
+						Image: "my-app:latest",
+						Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+					}},
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					PodIP:      "10.0.0.5",
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			}
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(binding, pod).
+				WithStatusSubresource(binding).
+				Build()
+
+			r := &SessionBindingReconciler{
+				Client:       client,
+				Scheme:       scheme,
+				CFClient:     &fakeCFClient{sessionExists: true},
+				Recorder:     &fakeRecorder{},
+				Clock:        &fakeClock{now: now},
+				ResyncPeriod: tt.resyncPeriod,
+				Jitter:       func() float64 { return 0 },
+			}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"},
+			})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if result.RequeueAfter != tt.want {
+				t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcile_GivesUpAfterMaxRetries(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+			Generation:        1,
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "broken-session",
+			TargetDeployment: "my-app",
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding).
+		WithStatusSubresource(binding).
+		Build()
+
+	cf := &fakeCFClient{sessionErr: fmt.Errorf("cloudflare API timeout")}
+	rec := &fakeRecorder{}
+	r := &SessionBindingReconciler{
+		Client:     client,
+		Scheme:     scheme,
+		CFClient:   cf,
+		Recorder:   rec,
+		Clock:      &fakeClock{now: now},
+		MaxRetries: 2,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	get := func() *v1alpha1.SessionBinding {
+		updated := &v1alpha1.SessionBinding{}
+		if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+			t.Fatalf("get binding: %v", err)
+		}
+		return updated
+	}
+
+	var result ctrl.Result
+	for i := 0; i < 3; i++ {
+		var err error
+		if result, err = r.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+	}
+	updated := get()
+	if updated.Status.Phase != v1alpha1.SessionBindingPhaseFailed {
+		t.Fatalf("phase = %q, want Failed", updated.Status.Phase)
+	}
+	if result.RequeueAfter != 0 || result.Requeue {
+		t.Errorf("expected no requeue after giving up, got %+v", result)
+	}
+	if ready := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionReady); ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != "Failed" {
+		t.Errorf("expected Ready=False with reason Failed, got %+v", ready)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, v1alpha1.ConditionStalled) {
+		t.Errorf("expected Stalled condition, got %+v", updated.Status.Conditions)
+	}
+	if !strings.Contains(strings.Join(rec.events, "\n"), "ReconcileGivenUp") {
+		t.Errorf("expected ReconcileGivenUp event, got %v", rec.events)
+	}
+
+	// A Failed binding is left alone until its spec changes.
+	calls := cf.sessionCalls
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if cf.sessionCalls != calls {
+		t.Errorf("Failed binding should not be retried without a spec change")
+	}
+
+	updated.Spec.UserID = "someone"
+	updated.Generation = 2
+	if err := client.Update(context.Background(), updated); err != nil {
+		t.Fatalf("update binding: %v", err)
+	}
+	cf.sessionErr = nil
+	cf.sessionExists = false
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	updated = get()
+	if cf.sessionCalls != calls+1 {
+		t.Errorf("expected a retry after the spec change")
+	}
+	if updated.Status.Phase == v1alpha1.SessionBindingPhaseFailed || updated.Status.ConsecutiveFailures != 0 {
+		t.Errorf("expected reset after spec change, got phase %q failures %d", updated.Status.Phase, updated.Status.ConsecutiveFailures)
+	}
+}
+
+func TestReconcile_ObservedGenerationSetOnSuccess(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+			Generation:        3,
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "gen-session",
+			TargetDeployment: "my-app",
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding).
+		WithStatusSubresource(binding).
+		Build()
+
+	cf := &fakeCFClient{sessionErr: fmt.Errorf("cloudflare API timeout")}
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: cf,
+		Recorder: &fakeRecorder{},
+		Clock:    &fakeClock{now: now},
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	get := func() *v1alpha1.SessionBinding {
+		updated := &v1alpha1.SessionBinding{}
+		if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+			t.Fatalf("get binding: %v", err)
+		}
+		return updated
+	}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := get().Status.ObservedGeneration; got != 0 {
+		t.Errorf("ObservedGeneration after failed reconcile = %d, want 0", got)
+	}
+
+	cf.sessionErr = nil
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := get().Status.ObservedGeneration; got != 3 {
+		t.Errorf("ObservedGeneration after successful reconcile = %d, want 3", got)
+	}
+}
+
+func TestRemainingTTL(t *testing.T) {
+	creation := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		ttl        *int64
+		defaultTTL time.Duration
+		elapsed    time.Duration
+		want       time.Duration
+	}{
+		{"no TTL", nil, 0, time.Hour, 0},
+		{"half elapsed", int64Ptr(3600), 0, 30 * time.Minute, 30 * time.Minute},
+		{"already expired", int64Ptr(60), 0, time.Hour, 0},
+		{"unset TTL uses default", nil, 2 * time.Hour, time.Hour, time.Hour},
+		{"explicit zero ignores default", int64Ptr(0), 2 * time.Hour, time.Hour, 0},
+		{"explicit TTL overrides default", int64Ptr(600), 2 * time.Hour, time.Minute, 9 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &v1alpha1.SessionBinding{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(creation)},
+				Spec:       v1alpha1.SessionBindingSpec{TTLSeconds: tt.ttl},
+			}
+			r := &SessionBindingReconciler{Clock: &fakeClock{now: creation.Add(tt.elapsed)}, DefaultTTL: tt.defaultTTL}
+			if got := r.remainingTTL(binding); got != tt.want {
+				t.Errorf("remainingTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcile_AddsFinalizer(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "new-session",
+			TargetDeployment: "my-app",
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding).
+		WithStatusSubresource(binding).
+		Build()
+
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: &fakeCFClient{sessionExists: false},
+		Recorder: &fakeRecorder{},
+		Clock:    &fakeClock{now: now},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	updated := &v1alpha1.SessionBinding{}
+	if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(updated, sessionBindingFinalizer) {
+		t.Errorf("expected finalizer %q, got %v", sessionBindingFinalizer, updated.Finalizers)
+	}
+}
+
+func TestHandleDeletion_DeleteRouteFailureKeepsFinalizer(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deletionTime := metav1.NewTime(now)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			DeletionTimestamp: &deletionTime,
+			Finalizers:        []string{sessionBindingFinalizer},
+			CreationTimestamp: metav1.NewTime(now.Add(-1 * time.Hour)),
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "cleanup-session",
+			TargetDeployment: "my-app",
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding).
+		WithStatusSubresource(binding).
+		Build()
+
+	cf := &fakeCFClient{deleteErr: fmt.Errorf("cloudflare unavailable")}
+	rec := &fakeRecorder{}
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: cf,
+		Recorder: rec,
+		Clock:    &fakeClock{now: now},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	if _, err := r.Reconcile(context.Background(), req); err == nil {
+		t.Fatal("expected error so the deletion is requeued")
+	}
+
+	updated := &v1alpha1.SessionBinding{}
+	if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatalf("binding should still exist while cleanup is failing: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(updated, sessionBindingFinalizer) {
+		t.Error("finalizer should be kept when DeleteRoute fails")
+	}
+	if !strings.Contains(strings.Join(rec.events, "\n"), "Warning CleanupFailed") {
+		t.Errorf("expected CleanupFailed event, got %v", rec.events)
+	}
+
+	// Once Cloudflare recovers the finalizer is removed and the object goes away.
+	cf.deleteErr = nil
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := client.Get(context.Background(), req.NamespacedName, updated); !apierrors.IsNotFound(err) {
+		t.Errorf("expected binding to be deleted after cleanup, got err = %v", err)
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	tests := []struct {
+		name    string
+		spec    v1alpha1.SessionBindingSpec
+		wantErr bool
+	}{
+		{"deployment only", v1alpha1.SessionBindingSpec{TargetDeployment: "my-app"}, false},
+		{"selector only", v1alpha1.SessionBindingSpec{TargetSelector: selector}, false},
+		{"both", v1alpha1.SessionBindingSpec{TargetDeployment: "my-app", TargetSelector: selector}, true},
+		{"neither", v1alpha1.SessionBindingSpec{}, true},
+		{"empty selector", v1alpha1.SessionBindingSpec{TargetSelector: &metav1.LabelSelector{}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTarget(tt.spec); (err != nil) != tt.wantErr {
+				t.Errorf("validateTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconcileActive_TargetSelectorPicksReadyPod(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:      "selector-session",
+			TargetSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+	newPod := func(name, ip string, ready bool, labels map[string]string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  "web",
+					Image: "web:latest",
+					Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: ip},
+		}
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		return pod
+	}
+	web := map[string]string{"app": "web"}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			binding,
+			newPod("web-0", "10.0.0.10", false, web),
+			newPod("web-1", "10.0.0.11", true, web),
+			newPod("other-0", "10.0.0.20", true, map[string]string{"app": "other"}),
+		).
+		WithStatusSubresource(binding).
+		Build()
+
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: &fakeCFClient{sessionExists: true},
+		Recorder: &fakeRecorder{},
+		Clock:    &fakeClock{now: now},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	updated := &v1alpha1.SessionBinding{}
+	if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if updated.Status.Phase != v1alpha1.SessionBindingPhaseBound {
+		t.Fatalf("phase = %q, want Bound", updated.Status.Phase)
+	}
+	if updated.Status.BoundPod != "web-1" || updated.Status.RouteEndpoint != "10.0.0.11:8080" {
+		t.Errorf("bound to %q at %q, want web-1 at 10.0.0.11:8080", updated.Status.BoundPod, updated.Status.RouteEndpoint)
+	}
+	if updated.Status.ReadyReplicas != 1 || updated.Status.TotalReplicas != 2 {
+		t.Errorf("replicas = %d/%d ready, want 1/2", updated.Status.ReadyReplicas, updated.Status.TotalReplicas)
+	}
+
+	// No session pod is created in selector mode.
+	pod := &corev1.Pod{}
+	err := client.Get(context.Background(), types.NamespacedName{Name: "session-selector-session", Namespace: "default"}, pod)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected no session pod in selector mode, got err = %v", err)
+	}
+}
+
+func TestReconcileActive_ReroutesWhenBoundPodUnready(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+			Finalizers:        []string{sessionBindingFinalizer},
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:      "reroute-session",
+			TargetSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+		Status: v1alpha1.SessionBindingStatus{
+			Phase:         v1alpha1.SessionBindingPhaseBound,
+			BoundPod:      "web-0",
+			RouteEndpoint: "10.0.0.10:8080",
+		},
+	}
+	newPod := func(name, ip string, ready bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  "web",
+					Image: "web:latest",
+					Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: ip},
+		}
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		return pod
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding, newPod("web-0", "10.0.0.10", false), newPod("web-1", "10.0.0.11", true)).
+		WithStatusSubresource(binding).
+		Build()
+
+	cf := &fakeCFClient{sessionExists: true, storedRoutes: map[string]cloudflare.RouteValue{"reroute-session": storedRoute("10.0.0.10:8080", "")}}
+	rec := &fakeRecorder{}
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: cf,
+		Recorder: rec,
+		Clock:    &fakeClock{now: now},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	updated := &v1alpha1.SessionBinding{}
+	if err := client.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatalf("get binding: %v", err)
+	}
+	if updated.Status.BoundPod != "web-1" || updated.Status.RouteEndpoint != "10.0.0.11:8080" {
+		t.Errorf("bound to %q at %q, want web-1 at 10.0.0.11:8080", updated.Status.BoundPod, updated.Status.RouteEndpoint)
+	}
+	if cf.ensureCalls != 1 {
+		t.Errorf("EnsureRoute calls = %d, want 1", cf.ensureCalls)
+	}
+
+	found := false
+	for _, e := range rec.events {
+		if strings.Contains(e, "EndpointChanged") && strings.Contains(e, "from 10.0.0.10:8080 to 10.0.0.11:8080") {
 			found = true
-			break
 		}
 	}
 	if !found {
-		t.Errorf("expected CleanedUp event, got events: %v", rec.events)
+		t.Errorf("expected EndpointChanged event, got %v", rec.events)
+	}
+}
+
+func TestReconcileActive_EndpointChangedAfterPodRestart(t *testing.T) {
+	scheme := newTestScheme()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-binding",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now),
+			Finalizers:        []string{sessionBindingFinalizer},
+		},
+		Spec: v1alpha1.SessionBindingSpec{
+			SessionID:        "restart-session",
+			TargetDeployment: "my-app",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-restart-session", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "app",
+				Image: "my-app:latest",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "10.0.0.5",
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(binding, pod).
+		WithStatusSubresource(binding, pod).
+		Build()
+
+	cf := &fakeCFClient{sessionExists: true}
+	rec := &fakeRecorder{}
+	r := &SessionBindingReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		CFClient: cf,
+		Recorder: rec,
+		Clock:    &fakeClock{now: now},
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-binding", Namespace: "default"}}
+	setPod := func(ip string, ready bool) {
+		t.Helper()
+		current := &corev1.Pod{}
+		if err := client.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: "default"}, current); err != nil {
+			t.Fatalf("get pod: %v", err)
+		}
+		current.Status.PodIP = ip
+		current.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}
+		if ready {
+			current.Status.Conditions[0].Status = corev1.ConditionTrue
+		}
+		if err := client.Status().Update(ctx, current); err != nil {
+			t.Fatalf("update pod status: %v", err)
+		}
+	}
+	reconcile := func() *v1alpha1.SessionBinding {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		updated := &v1alpha1.SessionBinding{}
+		if err := client.Get(ctx, req.NamespacedName, updated); err != nil {
+			t.Fatalf("get binding: %v", err)
+		}
+		return updated
+	}
+
+	if got := reconcile(); got.Status.RouteEndpoint != "10.0.0.5:8080" {
+		t.Fatalf("initial RouteEndpoint = %q, want 10.0.0.5:8080", got.Status.RouteEndpoint)
+	}
+
+	// The pod restarts: unready while it comes back, then ready on a new IP.
+	setPod("", false)
+	if got := reconcile(); got.Status.Phase != v1alpha1.SessionBindingPhasePending || got.Status.RouteEndpoint != "10.0.0.5:8080" {
+		t.Fatalf("while waiting: phase %q RouteEndpoint %q, want Pending with the last endpoint kept",
+			got.Status.Phase, got.Status.RouteEndpoint)
+	}
+	setPod("10.0.0.6", true)
+	if got := reconcile(); got.Status.RouteEndpoint != "10.0.0.6:8080" {
+		t.Fatalf("after restart RouteEndpoint = %q, want 10.0.0.6:8080", got.Status.RouteEndpoint)
+	}
+
+	var changed []string
+	for _, e := range rec.events {
+		if strings.Contains(e, "EndpointChanged") {
+			changed = append(changed, e)
+		}
+	}
+	if len(changed) != 1 || !strings.Contains(changed[0], "from 10.0.0.5:8080 to 10.0.0.6:8080") {
+		t.Errorf("EndpointChanged events = %v, want one from 10.0.0.5:8080 to 10.0.0.6:8080", changed)
+	}
+}
+
+func TestBindingsForPodAndDeployment(t *testing.T) {
+	scheme := newTestScheme()
+	bindings := []client.Object{
+		&v1alpha1.SessionBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "by-deployment", Namespace: "default"},
+			Spec:       v1alpha1.SessionBindingSpec{SessionID: "s1", TargetDeployment: "web"},
+			Status:     v1alpha1.SessionBindingStatus{BoundPod: "session-s1"},
+		},
+		&v1alpha1.SessionBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "by-selector", Namespace: "default"},
+			Spec: v1alpha1.SessionBindingSpec{
+				SessionID:      "s2",
+				TargetSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		},
+		&v1alpha1.SessionBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other"},
+			Spec:       v1alpha1.SessionBindingSpec{SessionID: "s3", TargetDeployment: "web"},
+		},
+	}
+	r := &SessionBindingReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(bindings...).Build(),
+		Scheme: scheme,
+	}
+	names := func(reqs []reconcile.Request) []string {
+		var out []string
+		for _, req := range reqs {
+			out = append(out, req.Name)
+		}
+		sort.Strings(out)
+		return out
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		got  []reconcile.Request
+		want []string
+	}{
+		{
+			name: "bound pod",
+			got:  r.bindingsForPod(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "session-s1", Namespace: "default"}}),
+			want: []string{"by-deployment"},
+		},
+		{
+			name: "pod matching selector",
+			got: r.bindingsForPod(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "web-0", Namespace: "default", Labels: map[string]string{"app": "web"},
+			}}),
+			want: []string{"by-selector"},
+		},
+		{
+			name: "unrelated pod",
+			got:  r.bindingsForPod(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"}}),
+			want: nil,
+		},
+		{
+			name: "target deployment",
+			got:  r.bindingsForDeployment(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}),
+			want: []string{"by-deployment"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(tt.got); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requests = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordBindingMetrics(t *testing.T) {
+	creation := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := types.NamespacedName{Namespace: "metrics", Name: "binding"}
+	t.Cleanup(func() { forgetBindingMetrics(key) })
+	binding := &v1alpha1.SessionBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, CreationTimestamp: metav1.NewTime(creation)},
+	}
+	histogramCount := func() uint64 {
+		m := &dto.Metric{}
+		if err := readyDuration.Write(m); err != nil {
+			t.Fatalf("reading histogram: %v", err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+	activeBefore := testutil.ToFloat64(activeBindings)
+	countBefore := histogramCount()
+
+	binding.Status.Phase = v1alpha1.SessionBindingPhasePending
+	recordBindingMetrics(key, binding, false, creation.Add(time.Second))
+	if got := histogramCount() - countBefore; got != 0 {
+		t.Fatalf("observed %d ready durations while pending, want 0", got)
+	}
+
+	binding.Status.Phase = v1alpha1.SessionBindingPhaseBound
+	recordBindingMetrics(key, binding, false, creation.Add(5*time.Second))
+	if got := histogramCount() - countBefore; got != 1 {
+		t.Fatalf("observed %d ready durations after binding, want 1", got)
+	}
+	if got := testutil.ToFloat64(activeBindings) - activeBefore; got != 1 {
+		t.Errorf("active bindings delta = %v, want 1", got)
+	}
+
+	// Dropping back to Pending and re-binding does not observe again.
+	binding.Status.Phase = v1alpha1.SessionBindingPhasePending
+	recordBindingMetrics(key, binding, true, creation.Add(time.Minute))
+	if got := testutil.ToFloat64(activeBindings) - activeBefore; got != 0 {
+		t.Errorf("active bindings delta = %v after unbinding, want 0", got)
+	}
+	binding.Status.Phase = v1alpha1.SessionBindingPhaseBound
+	recordBindingMetrics(key, binding, false, creation.Add(2*time.Minute))
+	if got := histogramCount() - countBefore; got != 1 {
+		t.Errorf("observed %d ready durations after re-binding, want 1", got)
+	}
+
+	forgetBindingMetrics(key)
+	if got := testutil.ToFloat64(activeBindings) - activeBefore; got != 0 {
+		t.Errorf("active bindings delta = %v after delete, want 0", got)
+	}
+}
+
+func TestReconcileResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		phase    v1alpha1.SessionBindingPhase
+		wasBound bool
+		err      error
+		want     string
+	}{
+		{"newly bound", v1alpha1.SessionBindingPhaseBound, false, nil, reconcileResultCreated},
+		{"still bound", v1alpha1.SessionBindingPhaseBound, true, nil, reconcileResultUpdated},
+		{"pending", v1alpha1.SessionBindingPhasePending, false, nil, reconcileResultUpdated},
+		{"expired", v1alpha1.SessionBindingPhaseExpired, true, nil, reconcileResultExpired},
+		{"error phase", v1alpha1.SessionBindingPhaseError, false, nil, reconcileResultError},
+		{"failed phase", v1alpha1.SessionBindingPhaseFailed, false, nil, reconcileResultError},
+		{"returned error", v1alpha1.SessionBindingPhaseBound, false, fmt.Errorf("boom"), reconcileResultError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &v1alpha1.SessionBinding{Status: v1alpha1.SessionBindingStatus{Phase: tt.phase}}
+			if got := reconcileResult(binding, tt.wasBound, tt.err); got != tt.want {
+				t.Errorf("reconcileResult() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcile_CountsNotFound(t *testing.T) {
+	scheme := newTestScheme()
+	r := &SessionBindingReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme:   scheme,
+		Recorder: &fakeRecorder{},
+		Clock:    &fakeClock{now: time.Now()},
+	}
+	counter := reconcileTotal.WithLabelValues(reconcileResultNotFound)
+	before := testutil.ToFloat64(counter)
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "missing", Namespace: "default"}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("notfound count delta = %v, want 1", got)
+	}
+}
+
+func TestSelectPodForSession(t *testing.T) {
+	pods := func(names ...string) []corev1.Pod {
+		out := make([]corev1.Pod, len(names))
+		for i, n := range names {
+			out[i].Name = n
+		}
+		return out
+	}
+
+	if got := selectPodForSession("s1", nil); got != nil {
+		t.Fatalf("expected nil for no pods, got %q", got.Name)
+	}
+
+	// Same pod set in any order always yields the same pod for a session.
+	first := selectPodForSession("session-abc", pods("web-0", "web-1", "web-2")).Name
+	for i := 0; i < 5; i++ {
+		if got := selectPodForSession("session-abc", pods("web-2", "web-0", "web-1")).Name; got != first {
+			t.Fatalf("selection not stable: got %q, want %q", got, first)
+		}
+	}
+
+	// Different sessions spread across pods.
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		seen[selectPodForSession(fmt.Sprintf("session-%d", i), pods("web-0", "web-1", "web-2")).Name] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected sessions to spread across pods, got %v", seen)
 	}
 }
 
@@ -539,9 +2097,10 @@ func TestIsPodReady(t *testing.T) {
 
 func TestPodEndpoint(t *testing.T) {
 	tests := []struct {
-		name string
-		pod  *corev1.Pod
-		want string
+		name       string
+		pod        *corev1.Pod
+		targetPort *intstr.IntOrString
+		want       string
 	}{
 		{
 			name: "pod with IP and port",
@@ -563,6 +2122,54 @@ func TestPodEndpoint(t *testing.T) {
 			},
 			want: "10.0.0.2:80",
 		},
+		{
+			name: "IPv6 pod IP is bracketed",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+					}},
+				},
+				Status: corev1.PodStatus{PodIP: "fd00::1"},
+			},
+			want: "[fd00::1]:8080",
+		},
+		{
+			name: "named target port across containers",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9090}}},
+						{Ports: []corev1.ContainerPort{{Name: "grpc", ContainerPort: 9000}, {Name: "http", ContainerPort: 8080}}},
+					},
+				},
+				Status: corev1.PodStatus{PodIP: "10.0.0.3"},
+			},
+			targetPort: ptrIntOrString(intstr.FromString("http")),
+			want:       "10.0.0.3:8080",
+		},
+		{
+			name: "named target port not declared",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9090}}}},
+				},
+				Status: corev1.PodStatus{PodIP: "10.0.0.4"},
+			},
+			targetPort: ptrIntOrString(intstr.FromString("http")),
+			want:       "",
+		},
+		{
+			name: "numeric target port overrides declared ports",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Ports: []corev1.ContainerPort{{ContainerPort: 9090}}}},
+				},
+				Status: corev1.PodStatus{PodIP: "10.0.0.5"},
+			},
+			targetPort: ptrIntOrString(intstr.FromInt(3000)),
+			want:       "10.0.0.5:3000",
+		},
 		{
 			name: "pod without IP",
 			pod: &corev1.Pod{
@@ -574,9 +2181,11 @@ func TestPodEndpoint(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := podEndpoint(tt.pod); got != tt.want {
+			if got := podEndpoint(tt.pod, tt.targetPort); got != tt.want {
 				t.Errorf("podEndpoint() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
+
+func ptrIntOrString(v intstr.IntOrString) *intstr.IntOrString { return &v }
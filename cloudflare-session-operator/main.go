@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Creme-ala-creme/cloudflare-session-operator/api/v1alpha1"
 	"github.com/Creme-ala-creme/cloudflare-session-operator/controllers"
+	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/clock"
 	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/cloudflare"
 	"github.com/go-logr/stdr"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,9 +23,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
+// version is injected at build time via -ldflags "-X main.version=<version>"
+var version = ""
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -44,9 +52,40 @@ func validateCredentials() error {
 	if os.Getenv("CLOUDFLARE_API_TOKEN") == "" {
 		return fmt.Errorf("CLOUDFLARE_API_TOKEN is required (set CLOUDFLARE_DRY_RUN=true to skip)")
 	}
+	// Older credentials secrets predate this key; name it so upgrades fail clearly.
+	if os.Getenv("CLOUDFLARE_KV_NAMESPACE_ID") == "" {
+		return fmt.Errorf("CLOUDFLARE_KV_NAMESPACE_ID is required: add kv_namespace_id to the credentials secret (set CLOUDFLARE_DRY_RUN=true to skip)")
+	}
 	return nil
 }
 
+// newCloudflareClient builds the operator-wide Cloudflare client from the
+// environment. Unless requireCredentials is set, missing credentials are allowed
+// so the operator can serve bindings that bring their own credentialsSecretRef;
+// bindings relying on the operator's credentials then report MissingCredentials.
+func newCloudflareClient(requireCredentials bool) (*cloudflare.APIClient, error) {
+	if requireCredentials {
+		if err := validateCredentials(); err != nil {
+			return nil, err
+		}
+		return cloudflare.NewClientFromEnvStrict()
+	}
+	c := cloudflare.NewClientFromEnv().(*cloudflare.APIClient)
+	if c.HasCredentials() {
+		if err := c.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid Cloudflare client configuration: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// pingCloudflare verifies Cloudflare connectivity and credentials with a bounded timeout.
+func pingCloudflare(c cloudflare.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return c.Ping(ctx)
+}
+
 // resolveWatchNamespace determines which namespace the operator should watch.
 // Priority: WATCH_NAMESPACE env > POD_NAMESPACE env > empty (all namespaces).
 func resolveWatchNamespace() string {
@@ -56,47 +95,110 @@ func resolveWatchNamespace() string {
 	return os.Getenv("POD_NAMESPACE")
 }
 
+// defaultTTLSecondsFromEnv reads DEFAULT_TTL_SECONDS, the environment fallback for
+// --default-ttl-seconds. Unset or invalid values mean no default TTL.
+func defaultTTLSecondsFromEnv() int64 {
+	v, err := strconv.ParseInt(os.Getenv("DEFAULT_TTL_SECONDS"), 10, 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+// metricsServerOptions configures the metrics endpoint. When secure, it is served
+// over HTTPS (self-signed unless certs are mounted) and each scrape must carry a
+// token the API server authenticates and authorizes for GET on the /metrics
+// nonResourceURL. controller-runtime's filter caches both review decisions, so
+// scrapes do not hit the API server every time.
+func metricsServerOptions(bindAddress string, secure bool) metricsserver.Options {
+	opts := metricsserver.Options{BindAddress: bindAddress}
+	if secure {
+		opts.SecureServing = true
+		opts.FilterProvider = filters.WithAuthenticationAndAuthorization
+	}
+	return opts
+}
+
+// cacheOptions configures the manager's cache. The informer resync period is
+// left at controller-runtime's default; drift repair requeues only bound
+// SessionBindings (see --resync-period).
+func cacheOptions(watchNS string) cache.Options {
+	opts := cache.Options{}
+	if watchNS != "" {
+		opts.DefaultNamespaces = map[string]cache.Config{
+			watchNS: {},
+		}
+	}
+	return opts
+}
+
 func main() {
 	var metricsAddr string
+	var secureMetrics bool
 	var probeAddr string
 	var enableLeaderElection bool
+	var leaderElectionID string
+	var maxReconcileRetries int
+	var defaultTTLSeconds int64
+	var expiryGracePeriod time.Duration
+	var enableWebhooks bool
+	var podReadyRequeueInterval time.Duration
+	var reconcileTimeout time.Duration
+	var resyncPeriod time.Duration
+	var requireCredentials bool
 
-	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080",
+		"The address the metric endpoint binds to. Use 127.0.0.1:8080 to keep it local, or 0 to disable it.")
+	flag.BoolVar(&secureMetrics, "metrics-secure", false,
+		"Serve metrics over HTTPS and require a bearer token authorized (via TokenReview/SubjectAccessReview) to get /metrics.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
+	// Leader election is on by default so multiple replicas never reconcile (and write
+	// Cloudflare routes) concurrently. It needs get/list/watch/create/update/patch/delete
+	// on coordination.k8s.io leases and create/patch on events in the operator's
+	// namespace; the Helm chart's leader-election Role grants these.
+	flag.BoolVar(&enableLeaderElection, "enable-leader-election", true,
+		"Enable leader election for controller manager. Disable only for single-replica local runs.")
+	flag.BoolVar(&enableLeaderElection, "leader-elect", true, "Deprecated: use --enable-leader-election.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "sessionbinding.cloudflare.example",
+		"Name of the Lease used for leader election. Replicas of the same operator must share it.")
+	flag.IntVar(&maxReconcileRetries, "max-reconcile-retries", 10, "Consecutive failures before a SessionBinding is marked Failed (0 retries forever).")
+	flag.Int64Var(&defaultTTLSeconds, "default-ttl-seconds", defaultTTLSecondsFromEnv(),
+		"TTL applied to SessionBindings without spec.ttlSeconds (0 disables; an explicit ttlSeconds of 0 never expires). Defaults to $DEFAULT_TTL_SECONDS.")
+	flag.DurationVar(&expiryGracePeriod, "session-expiry-grace-period", 30*time.Second,
+		"How long a session must stay missing in Cloudflare before its SessionBinding is marked Expired (0 expires immediately).")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the SessionBinding admission webhooks. Requires serving certificates in the webhook server's cert directory.")
+	flag.DurationVar(&podReadyRequeueInterval, "pod-ready-requeue-interval", 5*time.Second,
+		"How often to recheck a SessionBinding whose target pod is not ready yet.")
+	flag.DurationVar(&reconcileTimeout, "reconcile-timeout", 30*time.Second,
+		"Upper bound on a single SessionBinding reconcile, including Cloudflare retries (0 disables).")
+	flag.DurationVar(&resyncPeriod, "resync-period", 30*time.Minute,
+		"How often each bound SessionBinding is reconciled again, repairing Cloudflare routes changed out of band (0 disables).")
+	flag.BoolVar(&requireCredentials, "require-credentials", true,
+		"Exit at startup without operator-wide Cloudflare credentials. Disable when every SessionBinding sets credentialsSecretRef.")
 	flag.Parse()
 
 	logger := stdr.New(log.New(os.Stdout, "", log.LstdFlags))
 	ctrllog.SetLogger(logger)
 
-	// Issue #3: Fail-fast if Cloudflare credentials are missing.
-	if err := validateCredentials(); err != nil {
-		setupLog.Error(err, "credential validation failed")
+	if resyncPeriod < 0 {
+		setupLog.Error(fmt.Errorf("--resync-period must not be negative, got %s", resyncPeriod), "invalid flags")
 		os.Exit(1)
 	}
 
 	// Issue #8: Namespace-scoped cache to restrict the operator's watch scope.
-	cacheOpts := cache.Options{
-		SyncPeriod: func() *time.Duration {
-			d := 5 * time.Minute
-			return &d
-		}(),
-	}
-	if watchNS := resolveWatchNamespace(); watchNS != "" {
-		cacheOpts.DefaultNamespaces = map[string]cache.Config{
-			watchNS: {},
-		}
+	watchNS := resolveWatchNamespace()
+	if watchNS != "" {
 		setupLog.Info("restricting watch to namespace", "namespace", watchNS)
 	}
+	cacheOpts := cacheOptions(watchNS)
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: metricsAddr,
-		},
+		Scheme:                 scheme,
+		Metrics:                metricsServerOptions(metricsAddr, secureMetrics),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "sessionbinding.cloudflare.example",
+		LeaderElectionID:       leaderElectionID,
 		Cache:                  cacheOpts,
 	})
 	if err != nil {
@@ -104,19 +206,61 @@ func main() {
 		os.Exit(1)
 	}
 
-	cfClient := cloudflare.NewClientFromEnv()
+	// Issue #3: Fail-fast if Cloudflare credentials are missing, unless disabled.
+	cfClient, err := newCloudflareClient(requireCredentials)
+	if err != nil {
+		setupLog.Error(err, "unable to configure Cloudflare client")
+		os.Exit(1)
+	}
+	cfClient.UserAgent = cloudflare.UserAgentForVersion(version)
+	clk := clock.Real{}
+	cfClient.Clock = clk
+
+	// Fail fast on bad credentials rather than erroring in every reconcile. Other
+	// failures (e.g. a network blip) are left to per-reconcile retries.
+	if !cfClient.HasCredentials() {
+		setupLog.Info("no operator-wide Cloudflare credentials; bindings without credentialsSecretRef will report MissingCredentials")
+	} else if err := pingCloudflare(cfClient); err != nil {
+		if errors.Is(err, cloudflare.ErrAuthFailed) {
+			setupLog.Error(err, "Cloudflare credentials rejected")
+			os.Exit(1)
+		}
+		setupLog.Error(err, "Cloudflare connectivity check failed; continuing")
+	}
 
 	if err = (&controllers.SessionBindingReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		CFClient: cfClient,
-		Recorder: mgr.GetEventRecorderFor("sessionbinding-controller"),
-		Clock:    controllers.RealClock{},
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		CFClient:                cfClient,
+		Recorder:                mgr.GetEventRecorderFor("sessionbinding-controller"),
+		Clock:                   clk,
+		MaxRetries:              int32(maxReconcileRetries),
+		DefaultTTL:              time.Duration(defaultTTLSeconds) * time.Second,
+		ExpiryGracePeriod:       expiryGracePeriod,
+		PodReadyRequeueInterval: podReadyRequeueInterval,
+		ReconcileTimeout:        reconcileTimeout,
+		ResyncPeriod:            resyncPeriod,
+		APIReader:               mgr.GetAPIReader(),
+		NewCFClient: func(creds controllers.CloudflareCredentials) cloudflare.Client {
+			c := cfClient.CloneWithCredentials(creds.AccountID, creds.APIToken, creds.KVNamespace)
+			if creds.BaseURL != "" {
+				c.BaseURL = creds.BaseURL
+			}
+			return c
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SessionBinding")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		webhook := &v1alpha1.SessionBindingWebhook{DefaultTTLSeconds: defaultTTLSeconds}
+		if err := webhook.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SessionBinding")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -15,12 +15,24 @@ func TestValidateCredentials(t *testing.T) {
 		{
 			name: "all credentials present",
 			envVars: map[string]string{
-				"CLOUDFLARE_ACCOUNT_ID": "test-account",
-				"CLOUDFLARE_API_TOKEN":  "test-token",
-				"CLOUDFLARE_DRY_RUN":    "",
+				"CLOUDFLARE_ACCOUNT_ID":      "test-account",
+				"CLOUDFLARE_API_TOKEN":       "test-token",
+				"CLOUDFLARE_KV_NAMESPACE_ID": "test-ns",
+				"CLOUDFLARE_DRY_RUN":         "",
 			},
 			wantErr: false,
 		},
+		{
+			name: "missing KV namespace",
+			envVars: map[string]string{
+				"CLOUDFLARE_ACCOUNT_ID":      "test-account",
+				"CLOUDFLARE_API_TOKEN":       "test-token",
+				"CLOUDFLARE_KV_NAMESPACE_ID": "",
+				"CLOUDFLARE_DRY_RUN":         "",
+			},
+			wantErr:   true,
+			errSubstr: "kv_namespace_id",
+		},
 		{
 			name: "missing account ID",
 			envVars: map[string]string{
@@ -161,3 +173,76 @@ func containsSubstr(s, substr string) bool {
 	}
 	return false
 }
+
+func TestDefaultTTLSecondsFromEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  int64
+	}{
+		{name: "unset", value: "", want: 0},
+		{name: "valid", value: "3600", want: 3600},
+		{name: "negative", value: "-5", want: 0},
+		{name: "invalid", value: "1h", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEFAULT_TTL_SECONDS", tt.value)
+			if got := defaultTTLSecondsFromEnv(); got != tt.want {
+				t.Errorf("defaultTTLSecondsFromEnv() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsServerOptions(t *testing.T) {
+	plain := metricsServerOptions(":8080", false)
+	if plain.SecureServing || plain.FilterProvider != nil {
+		t.Errorf("insecure options = %+v, want plain HTTP without a filter", plain)
+	}
+	secure := metricsServerOptions(":8443", true)
+	if !secure.SecureServing || secure.FilterProvider == nil || secure.BindAddress != ":8443" {
+		t.Errorf("secure options = %+v, want HTTPS with an auth filter on :8443", secure)
+	}
+}
+
+func TestCacheOptions(t *testing.T) {
+	all := cacheOptions("")
+	if all.SyncPeriod != nil {
+		t.Errorf("SyncPeriod = %v, want the controller-runtime default", *all.SyncPeriod)
+	}
+	if all.DefaultNamespaces != nil {
+		t.Errorf("DefaultNamespaces = %v, want all namespaces", all.DefaultNamespaces)
+	}
+	scoped := cacheOptions("sessions")
+	if _, ok := scoped.DefaultNamespaces["sessions"]; !ok || len(scoped.DefaultNamespaces) != 1 {
+		t.Errorf("DefaultNamespaces = %v, want only sessions", scoped.DefaultNamespaces)
+	}
+}
+
+func TestNewCloudflareClient(t *testing.T) {
+	t.Setenv("CLOUDFLARE_ACCOUNT_ID", "")
+	t.Setenv("CLOUDFLARE_API_TOKEN", "")
+	t.Setenv("CLOUDFLARE_KV_NAMESPACE_ID", "")
+	t.Setenv("CLOUDFLARE_DRY_RUN", "")
+
+	if _, err := newCloudflareClient(true); err == nil {
+		t.Fatalf("expected an error without credentials when they are required")
+	}
+	c, err := newCloudflareClient(false)
+	if err != nil {
+		t.Fatalf("newCloudflareClient(false) error = %v", err)
+	}
+	if c.HasCredentials() {
+		t.Errorf("client without credentials reports HasCredentials")
+	}
+
+	t.Setenv("CLOUDFLARE_ACCOUNT_ID", "acct")
+	t.Setenv("CLOUDFLARE_API_TOKEN", "token")
+	t.Setenv("CLOUDFLARE_KV_NAMESPACE_ID", "ns")
+	t.Setenv("CLOUDFLARE_API_BASE_URL", "not a url")
+	if _, err := newCloudflareClient(false); err == nil {
+		t.Fatalf("expected configured credentials to still be validated")
+	}
+}
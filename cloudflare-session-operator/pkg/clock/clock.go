@@ -0,0 +1,15 @@
+// Package clock is the time source shared by the reconciler and the Cloudflare
+// client, so tests can drive both from a single fake clock.
+package clock
+
+import "time"
+
+// Clock reports the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real implements Clock using the standard library.
+type Real struct{}
+
+func (Real) Now() time.Time { return time.Now() }
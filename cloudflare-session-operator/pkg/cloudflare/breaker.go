@@ -0,0 +1,97 @@
+package cloudflare
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// breakerCooldown is the default time the circuit breaker stays open before
+// letting a probe request through.
+const breakerCooldown = 30 * time.Second
+
+// breakerResult classifies a finished operation for the circuit breaker.
+type breakerResult int
+
+const (
+	// breakerSuccess means Cloudflare gave a definitive answer, including 4xx.
+	breakerSuccess breakerResult = iota
+	// breakerFailure means the operation ended on transport errors or
+	// retryable statuses after exhausting its retries.
+	breakerFailure
+	// breakerNeutral covers outcomes that say nothing about Cloudflare's
+	// health, such as caller cancellation.
+	breakerNeutral
+)
+
+// circuitBreaker is the mutable state behind APIClient's breaker. It is closed
+// while openedAt is zero.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// breakerAllow reports whether an operation may proceed. While the breaker is
+// open it returns ErrCircuitOpen. Once the cooldown has elapsed a single probe
+// is let through (half-open); its result closes or reopens the breaker.
+func (c *APIClient) breakerAllow() (probe bool, err error) {
+	if c.BreakerThreshold <= 0 {
+		return false, nil
+	}
+	b := &c.breaker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return false, nil
+	}
+	if b.probing || c.clockNow().Sub(b.openedAt) < c.breakerCooldown() {
+		return false, ErrCircuitOpen
+	}
+	b.probing = true
+	return true, nil
+}
+
+// breakerRecord feeds an operation's result to the breaker. The breaker opens
+// after BreakerThreshold consecutive failures, or on any failed probe.
+func (c *APIClient) breakerRecord(probe bool, result breakerResult) {
+	if c.BreakerThreshold <= 0 {
+		return
+	}
+	b := &c.breaker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	switch result {
+	case breakerSuccess:
+		b.failures = 0
+		b.openedAt = time.Time{}
+	case breakerFailure:
+		b.failures++
+		if probe || b.failures >= c.BreakerThreshold {
+			b.openedAt = c.clockNow()
+		}
+	}
+}
+
+// breakerResultFor classifies a doWithRetry operation from its retry outcome.
+func breakerResultFor(ctx context.Context, attempts int, outcome string) breakerResult {
+	switch {
+	case outcome == retryOutcomeSuccess:
+		return breakerSuccess
+	case attempts == 0 || ctx.Err() != nil:
+		return breakerNeutral
+	default:
+		return breakerFailure
+	}
+}
+
+func (c *APIClient) breakerCooldown() time.Duration {
+	if c.BreakerCooldown > 0 {
+		return c.BreakerCooldown
+	}
+	return breakerCooldown
+}
@@ -1,14 +1,27 @@
 package cloudflare
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/Creme-ala-creme/cloudflare-session-operator/pkg/clock"
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -18,8 +31,41 @@ const (
 	// sessionIDPattern validates session IDs to prevent injection.
 	sessionIDPattern = `^[a-zA-Z0-9_-]{1,128}$`
 
+	// defaultUserAgent is sent when APIClient.UserAgent is empty.
+	defaultUserAgent = "cloudflare-session-operator/1.0"
+
 	// httpTimeout is the default timeout for HTTP requests.
 	httpTimeout = 10 * time.Second
+
+	// perRequestTimeout is the default time limit for a single attempt within doWithRetry.
+	perRequestTimeout = 10 * time.Second
+
+	// maxRetries is the default number of times a failed request is retried.
+	maxRetries = 3
+
+	// retryBaseDelay is the default backoff upper bound before the first retry; it doubles per attempt.
+	retryBaseDelay = 500 * time.Millisecond
+
+	// listRoutesPageSize is the number of keys requested per KV list page (the API maximum).
+	listRoutesPageSize = 1000
+
+	// maxResponseBytes bounds how much of a Cloudflare response body is read.
+	maxResponseBytes = 10 << 20
+
+	// bulkDeleteBatchSize is the maximum number of keys per KV bulk delete request.
+	bulkDeleteBatchSize = 10000
+
+	// minKVExpirationTTL is the smallest expiration_ttl Workers KV accepts.
+	minKVExpirationTTL = 60 * time.Second
+
+	// maxRetryAfter caps how long a Retry-After header can make us wait.
+	maxRetryAfter = 30 * time.Second
+
+	// Connection pool defaults. Every request goes to the same API host, so the
+	// per-host idle limit (net/http defaults to 2) is what bounds reuse.
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 32
+	defaultIdleConnTimeout     = 90 * time.Second
 )
 
 var sessionIDRegex = regexp.MustCompile(sessionIDPattern)
@@ -28,16 +74,160 @@ var sessionIDRegex = regexp.MustCompile(sessionIDPattern)
 type Client interface {
 	EnsureSession(ctx context.Context, sessionID string) (bool, error)
 	EnsureRoute(ctx context.Context, sessionID, endpoint string) error
+	EnsureRouteWithTTL(ctx context.Context, sessionID, endpoint string, ttl time.Duration) error
+	EnsureRouteWithMetadata(ctx context.Context, sessionID, endpoint string, ttl time.Duration, metadata RouteMetadata) error
 	DeleteRoute(ctx context.Context, sessionID string) error
+	DeleteRoutes(ctx context.Context, sessionIDs []string) error
+	ListRoutes(ctx context.Context) ([]RouteEntry, error)
+	GetRoute(ctx context.Context, sessionID string) (endpoint string, found bool, err error)
+	GetRouteValue(ctx context.Context, sessionID string) (value RouteValue, found bool, err error)
+	Ping(ctx context.Context) error
+}
+
+// RouteSchemaVersion is the version of the RouteValue shape EnsureRoute writes.
+// Bump it on incompatible changes so the Worker can branch on schemaVersion.
+const RouteSchemaVersion = 1
+
+// RouteValue is the JSON value stored in Workers KV for a session route.
+type RouteValue struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Endpoint      string    `json:"endpoint"`
+	SessionID     string    `json:"sessionID"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	// Metadata is omitted when empty.
+	Metadata *RouteMetadata `json:"metadata,omitempty"`
+}
+
+// RouteMetadata is optional context about a route for the Worker.
+type RouteMetadata struct {
+	// TargetDeployment is the deployment the session pod was cloned from.
+	TargetDeployment string `json:"targetDeployment,omitempty"`
+}
+
+// RouteEntry describes a session route stored in Workers KV.
+type RouteEntry struct {
+	SessionID string
+	// Expiration is when Cloudflare will expire the key; zero if it never expires.
+	Expiration time.Time
+	// Metadata is the arbitrary JSON metadata attached to the key, if any.
+	Metadata map[string]any
+}
+
+// cfAPIResponse is the standard Cloudflare v4 API response envelope.
+type cfAPIResponse struct {
+	Success    bool            `json:"success"`
+	Errors     []ErrorDetail   `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo *cfResultInfo   `json:"result_info,omitempty"`
+}
+
+type cfResultInfo struct {
+	Count  int    `json:"count"`
+	Cursor string `json:"cursor"`
+}
+
+// cfKVKey is a single entry from the KV keys list endpoint.
+type cfKVKey struct {
+	Name       string         `json:"name"`
+	Expiration int64          `json:"expiration,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
 }
 
 // APIClient is a lightweight implementation of Client built on top of the Cloudflare REST API.
 type APIClient struct {
-	HTTPClient   *http.Client
-	AccountID    string
-	APIToken     string
-	KVNamespace  string
-	DryRun       bool
+	HTTPClient  *http.Client
+	AccountID   string
+	APIToken    string
+	KVNamespace string
+	DryRun      bool
+	// BaseURL overrides the Cloudflare API base URL; empty means cloudflareAPIBase.
+	BaseURL string
+	// UserAgent is sent with every request; empty means defaultUserAgent.
+	// Use UserAgentForVersion to build one from the operator's build version.
+	UserAgent string
+	// DebugHTTP logs every request and response (Authorization redacted) using the
+	// logger from the request context.
+	DebugHTTP bool
+
+	// MaxRetries is the number of retries after a failed request. Zero means
+	// maxRetries; a negative value disables retries.
+	MaxRetries int
+	// RetryBaseDelay is the backoff ceiling before the first retry. Zero means retryBaseDelay.
+	RetryBaseDelay time.Duration
+	// PerRequestTimeout bounds each attempt so one slow attempt cannot consume the
+	// whole operation budget. Zero means perRequestTimeout; the caller's context
+	// still bounds the total time across retries.
+	PerRequestTimeout time.Duration
+
+	// BreakerThreshold is the number of consecutive failed operations after which
+	// the client fails fast with ErrCircuitOpen instead of calling Cloudflare.
+	// Zero disables the circuit breaker.
+	BreakerThreshold int
+	// BreakerCooldown is how long the breaker stays open before a single probe
+	// request is allowed through. Zero means breakerCooldown.
+	BreakerCooldown time.Duration
+
+	// Clock is the time source for route updatedAt stamps, the circuit breaker
+	// and Retry-After dates.
+	// Nil means the system clock.
+	Clock clock.Clock
+
+	// logger is used when the request context carries no logger; see WithLogger.
+	logger logr.Logger
+
+	rngMu   sync.Mutex
+	rng     *rand.Rand
+	breaker circuitBreaker
+}
+
+// Option configures an APIClient built by NewClient.
+type Option func(*APIClient)
+
+// WithHTTPClient makes the client send requests through hc, e.g. one whose
+// transport adds tracing or metrics. A nil hc keeps the default.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *APIClient) {
+		if hc != nil {
+			c.HTTPClient = hc
+		}
+	}
+}
+
+// WithBaseURL overrides the Cloudflare API base URL; empty keeps cloudflareAPIBase.
+func WithBaseURL(baseURL string) Option {
+	return func(c *APIClient) { c.BaseURL = baseURL }
+}
+
+// WithCredentials sets the account, API token and Workers KV namespace.
+func WithCredentials(accountID, apiToken, kvNamespace string) Option {
+	return func(c *APIClient) {
+		c.AccountID = accountID
+		c.APIToken = apiToken
+		c.KVNamespace = kvNamespace
+	}
+}
+
+// WithLogger sets the logger used for requests whose context carries none.
+func WithLogger(logger logr.Logger) Option {
+	return func(c *APIClient) { c.logger = logger }
+}
+
+// WithClock sets the client's time source, e.g. a fake clock in tests.
+func WithClock(clk clock.Clock) Option {
+	return func(c *APIClient) { c.Clock = clk }
+}
+
+// NewClient creates an APIClient with the default HTTP client and connection
+// pool, then applies opts in order. It does not read the environment.
+func NewClient(opts ...Option) *APIClient {
+	c := &APIClient{
+		HTTPClient: &http.Client{Timeout: httpTimeout, Transport: newTransport()},
+		logger:     logr.Discard(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // NewClientFromEnv creates a Client using environment variables for configuration.
@@ -46,14 +236,166 @@ type APIClient struct {
 //   - CLOUDFLARE_API_TOKEN
 //   - CLOUDFLARE_KV_NAMESPACE_ID
 //   - CLOUDFLARE_DRY_RUN (optional, "true" to enable dry-run mode)
+//   - CLOUDFLARE_API_BASE_URL (optional, overrides the Cloudflare API base URL)
+//   - CLOUDFLARE_DEBUG_HTTP (optional, "true" to log requests and responses)
+//   - CLOUDFLARE_MAX_RETRIES (optional, integer retry count)
+//   - CLOUDFLARE_RETRY_BASE_DELAY (optional, Go duration such as "250ms")
+//   - CLOUDFLARE_BREAKER_THRESHOLD (optional, consecutive failures that open the circuit breaker)
+//   - CLOUDFLARE_BREAKER_COOLDOWN (optional, Go duration the breaker stays open)
+//   - CLOUDFLARE_MAX_IDLE_CONNS (optional, idle connection pool size)
+//   - CLOUDFLARE_MAX_IDLE_CONNS_PER_HOST (optional, idle connections kept to the API host)
+//   - CLOUDFLARE_IDLE_CONN_TIMEOUT (optional, Go duration before idle connections close)
+//
+// Unparseable optional values fall back to the defaults. The client is not
+// validated; use NewClientFromEnvStrict to fail fast on missing configuration.
 func NewClientFromEnv() Client {
+	return newClientFromEnv()
+}
+
+// NewClientFromEnvStrict is like NewClientFromEnv but returns an error if the
+// resulting configuration fails Validate.
+func NewClientFromEnvStrict() (*APIClient, error) {
+	c := newClientFromEnv()
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Cloudflare client configuration: %w", err)
+	}
+	return c, nil
+}
+
+// CloneWithCredentials returns a new client with the given account credentials and the
+// receiver's remaining settings (HTTP client, base URL, retries, dry-run). It lets
+// one operator serve several Cloudflare accounts.
+func (c *APIClient) CloneWithCredentials(accountID, apiToken, kvNamespace string) *APIClient {
 	return &APIClient{
-		HTTPClient:  &http.Client{Timeout: httpTimeout},
-		AccountID:   os.Getenv("CLOUDFLARE_ACCOUNT_ID"),
-		APIToken:    os.Getenv("CLOUDFLARE_API_TOKEN"),
-		KVNamespace: os.Getenv("CLOUDFLARE_KV_NAMESPACE_ID"),
-		DryRun:      strings.EqualFold(os.Getenv("CLOUDFLARE_DRY_RUN"), "true"),
+		HTTPClient:        c.HTTPClient,
+		AccountID:         accountID,
+		APIToken:          apiToken,
+		KVNamespace:       kvNamespace,
+		DryRun:            c.DryRun,
+		BaseURL:           c.BaseURL,
+		UserAgent:         c.UserAgent,
+		DebugHTTP:         c.DebugHTTP,
+		MaxRetries:        c.MaxRetries,
+		RetryBaseDelay:    c.RetryBaseDelay,
+		PerRequestTimeout: c.PerRequestTimeout,
+		BreakerThreshold:  c.BreakerThreshold,
+		BreakerCooldown:   c.BreakerCooldown,
+		Clock:             c.Clock,
+		logger:            c.logger,
+	}
+}
+
+func newClientFromEnv() *APIClient {
+	c := NewClient(
+		WithHTTPClient(&http.Client{Timeout: httpTimeout, Transport: newTransportFromEnv()}),
+		WithBaseURL(os.Getenv("CLOUDFLARE_API_BASE_URL")),
+		WithCredentials(
+			os.Getenv("CLOUDFLARE_ACCOUNT_ID"),
+			os.Getenv("CLOUDFLARE_API_TOKEN"),
+			os.Getenv("CLOUDFLARE_KV_NAMESPACE_ID"),
+		),
+	)
+	c.DryRun = strings.EqualFold(os.Getenv("CLOUDFLARE_DRY_RUN"), "true")
+	c.DebugHTTP = strings.EqualFold(os.Getenv("CLOUDFLARE_DEBUG_HTTP"), "true")
+	if v, err := strconv.Atoi(os.Getenv("CLOUDFLARE_MAX_RETRIES")); err == nil {
+		c.MaxRetries = v
+	}
+	if d, err := time.ParseDuration(os.Getenv("CLOUDFLARE_RETRY_BASE_DELAY")); err == nil && d > 0 {
+		c.RetryBaseDelay = d
+	}
+	if v, err := strconv.Atoi(os.Getenv("CLOUDFLARE_BREAKER_THRESHOLD")); err == nil && v > 0 {
+		c.BreakerThreshold = v
 	}
+	if d, err := time.ParseDuration(os.Getenv("CLOUDFLARE_BREAKER_COOLDOWN")); err == nil && d > 0 {
+		c.BreakerCooldown = d
+	}
+	return c
+}
+
+// newTransport clones http.DefaultTransport (keeping its proxy, dial and TLS
+// settings) with a connection pool sized for many concurrent reconciles.
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = defaultMaxIdleConns
+	t.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	t.IdleConnTimeout = defaultIdleConnTimeout
+	return t
+}
+
+// newTransportFromEnv is newTransport with the pool limits overridden from the
+// CLOUDFLARE_MAX_IDLE_CONNS* and CLOUDFLARE_IDLE_CONN_TIMEOUT variables.
+func newTransportFromEnv() *http.Transport {
+	t := newTransport()
+	if v, err := strconv.Atoi(os.Getenv("CLOUDFLARE_MAX_IDLE_CONNS")); err == nil && v >= 0 {
+		t.MaxIdleConns = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("CLOUDFLARE_MAX_IDLE_CONNS_PER_HOST")); err == nil && v > 0 {
+		t.MaxIdleConnsPerHost = v
+	}
+	if d, err := time.ParseDuration(os.Getenv("CLOUDFLARE_IDLE_CONN_TIMEOUT")); err == nil && d > 0 {
+		t.IdleConnTimeout = d
+	}
+	return t
+}
+
+// HasCredentials reports whether the client can make authenticated API calls.
+// Dry-run clients never call the API, so they count as having credentials.
+func (c *APIClient) HasCredentials() bool {
+	return c.DryRun || (c.AccountID != "" && c.APIToken != "" && c.KVNamespace != "")
+}
+
+// Validate reports every missing or malformed configuration value. Credentials
+// are not required in dry-run mode since no API calls are made.
+func (c *APIClient) Validate() error {
+	var errs []error
+	if !c.DryRun {
+		if c.AccountID == "" {
+			errs = append(errs, errors.New("AccountID (CLOUDFLARE_ACCOUNT_ID) is required"))
+		}
+		if c.APIToken == "" {
+			errs = append(errs, errors.New("APIToken (CLOUDFLARE_API_TOKEN) is required"))
+		}
+		if c.KVNamespace == "" {
+			errs = append(errs, errors.New("KVNamespace (CLOUDFLARE_KV_NAMESPACE_ID) is required"))
+		}
+	}
+	if u, err := url.Parse(c.apiBase()); err != nil {
+		errs = append(errs, fmt.Errorf("BaseURL %q is invalid: %w", c.BaseURL, err))
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("BaseURL %q must be an absolute http(s) URL", c.BaseURL))
+	}
+	return errors.Join(errs...)
+}
+
+// UserAgentForVersion returns a User-Agent such as
+// "cloudflare-session-operator/v1.2.3 go1.22.1". An empty version falls back to
+// defaultUserAgent's version.
+func UserAgentForVersion(version string) string {
+	if version == "" {
+		return defaultUserAgent + " " + runtime.Version()
+	}
+	return "cloudflare-session-operator/" + version + " " + runtime.Version()
+}
+
+func (c *APIClient) userAgent() string {
+	if c.UserAgent != "" {
+		return c.UserAgent
+	}
+	return defaultUserAgent
+}
+
+func (c *APIClient) apiBase() string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/")
+	}
+	return cloudflareAPIBase
+}
+
+func (c *APIClient) clockNow() time.Time {
+	if c.Clock != nil {
+		return c.Clock.Now()
+	}
+	return time.Now()
 }
 
 // ValidateSessionID checks that a session ID matches the expected pattern.
@@ -67,10 +409,57 @@ func ValidateSessionID(sessionID string) error {
 	return nil
 }
 
+// Ping verifies connectivity and that the API token is valid and active using the
+// token verify endpoint. Credential problems return an error matching ErrAuthFailed.
+func (c *APIClient) Ping(ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "Ping", "")
+	defer func() { endSpan(span, err) }()
+
+	if c.DryRun {
+		return nil
+	}
+
+	url := fmt.Sprintf("%s/accounts/%s/tokens/verify", c.apiBase(), c.AccountID)
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating token verify request: %w", err)
+		}
+		c.setAuthHeaders(req)
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("executing token verify request: %w", err)
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("verifying Cloudflare API token: %w", newAPIError("token verify", resp))
+	}
+
+	var envelope cfAPIResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&envelope); err != nil {
+		return fmt.Errorf("decoding token verify response: %w", err)
+	}
+	var result struct {
+		Status string `json:"status"`
+	}
+	if len(envelope.Result) > 0 {
+		_ = json.Unmarshal(envelope.Result, &result)
+	}
+	if !envelope.Success || result.Status != "active" {
+		return fmt.Errorf("%w: token status %q", ErrAuthFailed, result.Status)
+	}
+	return nil
+}
+
 // EnsureSession verifies a Cloudflare session exists via the Access API.
 // Returns (true, nil) if the session is active, (false, nil) if not found,
 // and (false, error) on transient failures.
-func (c *APIClient) EnsureSession(ctx context.Context, sessionID string) (bool, error) {
+func (c *APIClient) EnsureSession(ctx context.Context, sessionID string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "EnsureSession", sessionID)
+	defer func() { endSpan(span, err) }()
+
 	if err := ValidateSessionID(sessionID); err != nil {
 		return false, fmt.Errorf("invalid session ID: %w", err)
 	}
@@ -78,18 +467,19 @@ func (c *APIClient) EnsureSession(ctx context.Context, sessionID string) (bool,
 		return true, nil
 	}
 
-	url := fmt.Sprintf("%s/accounts/%s/access/sessions/%s", cloudflareAPIBase, c.AccountID, sessionID)
+	url := fmt.Sprintf("%s/accounts/%s/access/sessions/%s", c.apiBase(), c.AccountID, sessionID)
 	return c.doSessionCheck(ctx, url)
 }
 
 func (c *APIClient) doSessionCheck(ctx context.Context, url string) (bool, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return false, fmt.Errorf("creating session check request: %w", err)
-	}
-	c.setAuthHeaders(req)
-
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating session check request: %w", err)
+		}
+		c.setAuthHeaders(req)
+		return req, nil
+	})
 	if err != nil {
 		return false, fmt.Errorf("executing session check request: %w", err)
 	}
@@ -100,15 +490,31 @@ func (c *APIClient) doSessionCheck(ctx context.Context, url string) (bool, error
 		return true, nil
 	case resp.StatusCode == http.StatusNotFound:
 		return false, nil
-	case resp.StatusCode >= 500:
-		return false, fmt.Errorf("cloudflare server error: status %d", resp.StatusCode)
 	default:
-		return false, fmt.Errorf("unexpected status from cloudflare: %d", resp.StatusCode)
+		return false, newAPIError("session check", resp)
 	}
 }
 
-// EnsureRoute writes a session-to-endpoint mapping in Cloudflare Workers KV.
+// EnsureRoute writes a session-to-endpoint mapping in Cloudflare Workers KV as a
+// JSON RouteValue.
 func (c *APIClient) EnsureRoute(ctx context.Context, sessionID, endpoint string) error {
+	return c.EnsureRouteWithTTL(ctx, sessionID, endpoint, 0)
+}
+
+// EnsureRouteWithTTL writes a session-to-endpoint mapping that Workers KV expires
+// after ttl, so stale routes disappear even if DeleteRoute never runs. A zero ttl
+// writes a key that never expires; positive values below KV's 60s minimum are
+// rounded up.
+func (c *APIClient) EnsureRouteWithTTL(ctx context.Context, sessionID, endpoint string, ttl time.Duration) error {
+	return c.EnsureRouteWithMetadata(ctx, sessionID, endpoint, ttl, RouteMetadata{})
+}
+
+// EnsureRouteWithMetadata is EnsureRouteWithTTL with optional metadata included
+// in the written RouteValue.
+func (c *APIClient) EnsureRouteWithMetadata(ctx context.Context, sessionID, endpoint string, ttl time.Duration, metadata RouteMetadata) (err error) {
+	ctx, span := startSpan(ctx, "EnsureRoute", sessionID)
+	defer func() { endSpan(span, err) }()
+
 	if err := ValidateSessionID(sessionID); err != nil {
 		return fmt.Errorf("invalid session ID: %w", err)
 	}
@@ -120,32 +526,53 @@ func (c *APIClient) EnsureRoute(ctx context.Context, sessionID, endpoint string)
 	}
 
 	url := fmt.Sprintf("%s/accounts/%s/storage/kv/namespaces/%s/values/%s",
-		cloudflareAPIBase, c.AccountID, c.KVNamespace, sessionID)
-	return c.doKVWrite(ctx, url, endpoint)
-}
-
-func (c *APIClient) doKVWrite(ctx context.Context, url, value string) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, strings.NewReader(value))
+		c.apiBase(), c.AccountID, c.KVNamespace, sessionID)
+	if ttl > 0 {
+		ttl = max(ttl, minKVExpirationTTL)
+		url += "?expiration_ttl=" + strconv.FormatInt(int64(ttl/time.Second), 10)
+	}
+	value := RouteValue{
+		SchemaVersion: RouteSchemaVersion,
+		Endpoint:      endpoint,
+		SessionID:     sessionID,
+		UpdatedAt:     c.clockNow().UTC(),
+	}
+	if metadata != (RouteMetadata{}) {
+		value.Metadata = &metadata
+	}
+	payload, err := json.Marshal(value)
 	if err != nil {
-		return fmt.Errorf("creating KV write request: %w", err)
+		return fmt.Errorf("encoding route value: %w", err)
 	}
-	c.setAuthHeaders(req)
-	req.Header.Set("Content-Type", "text/plain")
+	return c.doKVWrite(ctx, url, payload)
+}
 
-	resp, err := c.HTTPClient.Do(req)
+func (c *APIClient) doKVWrite(ctx context.Context, url string, value []byte) error {
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(value))
+		if err != nil {
+			return nil, fmt.Errorf("creating KV write request: %w", err)
+		}
+		c.setAuthHeaders(req)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
 		return fmt.Errorf("executing KV write request: %w", err)
 	}
 	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("cloudflare KV write failed: status %d", resp.StatusCode)
+		return newAPIError("KV write", resp)
 	}
 	return nil
 }
 
 // DeleteRoute removes a session-to-endpoint mapping from Cloudflare Workers KV.
-func (c *APIClient) DeleteRoute(ctx context.Context, sessionID string) error {
+func (c *APIClient) DeleteRoute(ctx context.Context, sessionID string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteRoute", sessionID)
+	defer func() { endSpan(span, err) }()
+
 	if err := ValidateSessionID(sessionID); err != nil {
 		return fmt.Errorf("invalid session ID for route deletion: %w", err)
 	}
@@ -154,18 +581,76 @@ func (c *APIClient) DeleteRoute(ctx context.Context, sessionID string) error {
 	}
 
 	url := fmt.Sprintf("%s/accounts/%s/storage/kv/namespaces/%s/values/%s",
-		cloudflareAPIBase, c.AccountID, c.KVNamespace, sessionID)
+		c.apiBase(), c.AccountID, c.KVNamespace, sessionID)
 	return c.doKVDelete(ctx, url)
 }
 
-func (c *APIClient) doKVDelete(ctx context.Context, url string) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+// DeleteRoutes removes many session routes using the KV bulk delete endpoint,
+// sending at most bulkDeleteBatchSize keys per request. Every batch is attempted;
+// the returned error lists the session IDs that could not be deleted.
+func (c *APIClient) DeleteRoutes(ctx context.Context, sessionIDs []string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteRoutes", "")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes(attribute.Int("cloudflare.route_count", len(sessionIDs)))
+
+	var errs []error
+	valid := make([]string, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		if err := ValidateSessionID(id); err != nil {
+			errs = append(errs, fmt.Errorf("invalid session ID for route deletion: %w", err))
+			continue
+		}
+		valid = append(valid, id)
+	}
+	if c.DryRun {
+		return errors.Join(errs...)
+	}
+
+	url := fmt.Sprintf("%s/accounts/%s/storage/kv/namespaces/%s/bulk",
+		c.apiBase(), c.AccountID, c.KVNamespace)
+	for start := 0; start < len(valid); start += bulkDeleteBatchSize {
+		batch := valid[start:min(start+bulkDeleteBatchSize, len(valid))]
+		if err := c.doKVBulkDelete(ctx, url, batch); err != nil {
+			errs = append(errs, fmt.Errorf("deleting routes %s: %w", strings.Join(batch, ","), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *APIClient) doKVBulkDelete(ctx context.Context, url string, keys []string) error {
+	body, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("encoding KV bulk delete body: %w", err)
+	}
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("creating KV bulk delete request: %w", err)
+		}
+		c.setAuthHeaders(req)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
-		return fmt.Errorf("creating KV delete request: %w", err)
+		return fmt.Errorf("executing KV bulk delete request: %w", err)
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError("KV bulk delete", resp)
 	}
-	c.setAuthHeaders(req)
+	return nil
+}
 
-	resp, err := c.HTTPClient.Do(req)
+func (c *APIClient) doKVDelete(ctx context.Context, url string) error {
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating KV delete request: %w", err)
+		}
+		c.setAuthHeaders(req)
+		return req, nil
+	})
 	if err != nil {
 		return fmt.Errorf("executing KV delete request: %w", err)
 	}
@@ -175,15 +660,380 @@ func (c *APIClient) doKVDelete(ctx context.Context, url string) error {
 		return nil // already deleted
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("cloudflare KV delete failed: status %d", resp.StatusCode)
+		return newAPIError("KV delete", resp)
 	}
 	return nil
 }
 
+// GetRoute reads back the endpoint stored for a session. A missing key returns
+// found=false with no error. Values written as JSON objects (such as RouteValue)
+// have their "endpoint" field returned; plain-text values, as written by older
+// operator versions, are returned as-is.
+func (c *APIClient) GetRoute(ctx context.Context, sessionID string) (string, bool, error) {
+	value, found, err := c.GetRouteValue(ctx, sessionID)
+	return value.Endpoint, found, err
+}
+
+// GetRouteValue reads back the full value stored for a session. Plain-text
+// values from older operator versions are returned with only Endpoint set and
+// SchemaVersion 0, so callers can tell they need rewriting.
+func (c *APIClient) GetRouteValue(ctx context.Context, sessionID string) (_ RouteValue, _ bool, err error) {
+	ctx, span := startSpan(ctx, "GetRoute", sessionID)
+	defer func() { endSpan(span, err) }()
+
+	if err := ValidateSessionID(sessionID); err != nil {
+		return RouteValue{}, false, fmt.Errorf("invalid session ID: %w", err)
+	}
+	if c.DryRun {
+		return RouteValue{}, false, nil
+	}
+
+	url := fmt.Sprintf("%s/accounts/%s/storage/kv/namespaces/%s/values/%s",
+		c.apiBase(), c.AccountID, c.KVNamespace, sessionID)
+	return c.doKVRead(ctx, url)
+}
+
+func (c *APIClient) doKVRead(ctx context.Context, url string) (RouteValue, bool, error) {
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating KV read request: %w", err)
+		}
+		c.setAuthHeaders(req)
+		return req, nil
+	})
+	if err != nil {
+		return RouteValue{}, false, fmt.Errorf("executing KV read request: %w", err)
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return RouteValue{}, false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return RouteValue{}, false, newAPIError("KV read", resp)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return RouteValue{}, false, fmt.Errorf("reading KV value: %w", err)
+	}
+	var stored RouteValue
+	if err := json.Unmarshal(body, &stored); err == nil && stored.Endpoint != "" {
+		return stored, true, nil
+	}
+	return RouteValue{Endpoint: string(body)}, true, nil
+}
+
+// ListRoutes returns every key in the session KV namespace, following the list
+// endpoint's cursor until all pages have been read.
+func (c *APIClient) ListRoutes(ctx context.Context) (_ []RouteEntry, err error) {
+	ctx, span := startSpan(ctx, "ListRoutes", "")
+	defer func() { endSpan(span, err) }()
+
+	if c.DryRun {
+		return nil, nil
+	}
+
+	baseURL := fmt.Sprintf("%s/accounts/%s/storage/kv/namespaces/%s/keys",
+		c.apiBase(), c.AccountID, c.KVNamespace)
+
+	var routes []RouteEntry
+	cursor := ""
+	for {
+		q := url.Values{}
+		q.Set("limit", strconv.Itoa(listRoutesPageSize))
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		keys, next, err := c.doKVListKeys(ctx, baseURL+"?"+q.Encode())
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			entry := RouteEntry{SessionID: k.Name, Metadata: k.Metadata}
+			if k.Expiration > 0 {
+				entry.Expiration = time.Unix(k.Expiration, 0).UTC()
+			}
+			routes = append(routes, entry)
+		}
+		if next == "" {
+			return routes, nil
+		}
+		cursor = next
+	}
+}
+
+func (c *APIClient) doKVListKeys(ctx context.Context, listURL string) ([]cfKVKey, string, error) {
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating KV list request: %w", err)
+		}
+		c.setAuthHeaders(req)
+		return req, nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("executing KV list request: %w", err)
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", newAPIError("KV list", resp)
+	}
+
+	var envelope cfAPIResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&envelope); err != nil {
+		return nil, "", fmt.Errorf("decoding KV list response: %w", err)
+	}
+	if !envelope.Success {
+		return nil, "", &APIError{Op: "KV list", StatusCode: resp.StatusCode, Errors: envelope.Errors}
+	}
+
+	var keys []cfKVKey
+	if err := json.Unmarshal(envelope.Result, &keys); err != nil {
+		return nil, "", fmt.Errorf("decoding KV list result: %w", err)
+	}
+	var cursor string
+	if envelope.ResultInfo != nil {
+		cursor = envelope.ResultInfo.Cursor
+	}
+	return keys, cursor, nil
+}
+
+// doWithRetry sends the request built by newReq, retrying transport errors, 429s and
+// 5xx responses up to MaxRetries times. Each attempt gets its own context limited by
+// PerRequestTimeout, which newReq must use. The final response is returned as-is so
+// the caller can map its status; the caller must close its body. While the circuit
+// breaker is open it returns ErrCircuitOpen without sending anything.
+func (c *APIClient) doWithRetry(ctx context.Context, newReq func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	retries := c.maxRetries()
+	var lastErr error
+	var retryAfter time.Duration
+	probe, err := c.breakerAllow()
+	if err != nil {
+		return nil, err
+	}
+	// Anything but a definitive response (including cancellation) counts as exhausted.
+	var method string
+	attempts, outcome := 0, retryOutcomeExhausted
+	defer func() {
+		recordAttempts(method, attempts, outcome)
+		c.breakerRecord(probe, breakerResultFor(ctx, attempts, outcome))
+		clientSpan(ctx).SetAttributes(attribute.Int("cloudflare.retries", max(attempts-1, 0)))
+	}()
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff(attempt)
+			if retryAfter > 0 {
+				delay = retryAfter
+			}
+			if err := sleepContext(ctx, delay); err != nil {
+				if lastErr != nil {
+					return nil, lastErr
+				}
+				return nil, err
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, c.perRequestTimeout())
+		req, err := newReq(attemptCtx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		method = req.Method
+		attempts++
+		req.Header.Set("User-Agent", c.userAgent())
+		// Set explicitly so large KV list pages are compressed; this also turns off
+		// net/http's transparent decompression, so decompressBody handles it.
+		req.Header.Set("Accept-Encoding", "gzip")
+		if c.DebugHTTP {
+			c.logRequest(ctx, req, attempt)
+		}
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			cancel()
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			c.log(ctx).V(1).Info("cloudflare request failed; retrying", "attempt", attempt, "error", err.Error())
+			lastErr = err
+			retryAfter = 0
+			continue
+		}
+		recordRateLimit(resp)
+		clientSpan(ctx).SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		if err := decompressBody(resp); err != nil {
+			drainAndClose(resp.Body)
+			cancel()
+			c.log(ctx).V(1).Info("cloudflare response could not be decompressed; retrying", "attempt", attempt, "error", err.Error())
+			lastErr = err
+			retryAfter = 0
+			continue
+		}
+		if c.DebugHTTP {
+			c.logResponse(ctx, req, resp)
+		}
+		// The attempt context must outlive doWithRetry while the caller reads the body.
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		if !isRetryableStatus(resp.StatusCode) {
+			outcome = retryOutcomeSuccess
+			return resp, nil
+		}
+		if attempt == retries {
+			return resp, nil
+		}
+		retryAfter = 0
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), c.clockNow()); ok {
+				retryAfter = min(d, maxRetryAfter)
+			}
+		}
+		lastErr = newAPIError("request", resp)
+		drainAndClose(resp.Body)
+		c.log(ctx).V(1).Info("cloudflare returned retryable status; retrying", "attempt", attempt, "status", resp.StatusCode)
+	}
+	return nil, lastErr
+}
+
+// backoff returns a delay for the given retry attempt (1-based) using full jitter:
+// a random duration in [0, RetryBaseDelay*2^(attempt-1)]. Jitter keeps many
+// operators from retrying in lockstep after a Cloudflare rate limit.
+func (c *APIClient) backoff(attempt int) time.Duration {
+	ceiling := c.retryBaseDelay() << (attempt - 1)
+
+	c.rngMu.Lock()
+	defer c.rngMu.Unlock()
+	if c.rng == nil {
+		c.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return time.Duration(c.rng.Int63n(int64(ceiling) + 1))
+}
+
+func (c *APIClient) maxRetries() int {
+	switch {
+	case c.MaxRetries < 0:
+		return 0
+	case c.MaxRetries == 0:
+		return maxRetries
+	default:
+		return c.MaxRetries
+	}
+}
+
+func (c *APIClient) perRequestTimeout() time.Duration {
+	if c.PerRequestTimeout > 0 {
+		return c.PerRequestTimeout
+	}
+	return perRequestTimeout
+}
+
+func (c *APIClient) retryBaseDelay() time.Duration {
+	if c.RetryBaseDelay > 0 {
+		return c.RetryBaseDelay
+	}
+	return retryBaseDelay
+}
+
+// parseRetryAfter parses a Retry-After header in either delta-seconds or HTTP-date form.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
 func (c *APIClient) setAuthHeaders(req *http.Request) {
 	req.Header.Set("Authorization", "Bearer "+c.APIToken)
 }
 
+// cancelOnClose releases a per-attempt context once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// gzipBody inflates a gzip-encoded response body and closes both readers.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.body.Close()
+}
+
+// decompressBody replaces a gzip-encoded response body with its decompressed
+// stream so callers, error decoding and debug logging all see plain bytes.
+// Identity responses are left alone.
+func decompressBody(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	switch {
+	case errors.Is(err, io.EOF):
+		// Empty body (e.g. 204): nothing to inflate.
+	case err != nil:
+		return fmt.Errorf("decompressing cloudflare response: %w", err)
+	default:
+		resp.Body = &gzipBody{Reader: zr, body: resp.Body}
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// log returns the logger from ctx, falling back to the client's own logger.
+func (c *APIClient) log(ctx context.Context) logr.Logger {
+	if logger, err := logr.FromContext(ctx); err == nil {
+		return logger
+	}
+	if c.logger.GetSink() == nil {
+		return logr.Discard()
+	}
+	return c.logger
+}
+
 // drainAndClose reads remaining bytes and closes the body to allow connection reuse.
 func drainAndClose(body io.ReadCloser) {
 	_, _ = io.Copy(io.Discard, body)
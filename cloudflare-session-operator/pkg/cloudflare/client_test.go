@@ -1,10 +1,29 @@
 package cloudflare
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestValidateSessionID(t *testing.T) {
@@ -112,11 +131,12 @@ func TestEnsureSession(t *testing.T) {
 			defer srv.Close()
 
 			client := &APIClient{
-				HTTPClient:  srv.Client(),
-				AccountID:   "test-account",
-				APIToken:    "test-token",
-				KVNamespace: "test-ns",
-				DryRun:      tt.dryRun,
+				HTTPClient:     srv.Client(),
+				AccountID:      "test-account",
+				APIToken:       "test-token",
+				KVNamespace:    "test-ns",
+				DryRun:         tt.dryRun,
+				RetryBaseDelay: time.Millisecond,
 			}
 
 			// Override the base URL by using a custom transport
@@ -198,11 +218,12 @@ func TestEnsureRoute(t *testing.T) {
 			defer srv.Close()
 
 			client := &APIClient{
-				HTTPClient:  srv.Client(),
-				AccountID:   "test-account",
-				APIToken:    "test-token",
-				KVNamespace: "test-ns",
-				DryRun:      tt.dryRun,
+				HTTPClient:     srv.Client(),
+				AccountID:      "test-account",
+				APIToken:       "test-token",
+				KVNamespace:    "test-ns",
+				DryRun:         tt.dryRun,
+				RetryBaseDelay: time.Millisecond,
 			}
 
 			if !tt.dryRun && tt.sessionID != "" && tt.endpoint != "" && ValidateSessionID(tt.sessionID) == nil {
@@ -273,11 +294,12 @@ func TestDeleteRoute(t *testing.T) {
 			defer srv.Close()
 
 			client := &APIClient{
-				HTTPClient:  srv.Client(),
-				AccountID:   "test-account",
-				APIToken:    "test-token",
-				KVNamespace: "test-ns",
-				DryRun:      tt.dryRun,
+				HTTPClient:     srv.Client(),
+				AccountID:      "test-account",
+				APIToken:       "test-token",
+				KVNamespace:    "test-ns",
+				DryRun:         tt.dryRun,
+				RetryBaseDelay: time.Millisecond,
 			}
 
 			if !tt.dryRun && tt.sessionID != "" && ValidateSessionID(tt.sessionID) == nil {
@@ -312,3 +334,1131 @@ func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	}
 	return http.DefaultTransport.RoundTrip(req)
 }
+
+func TestBackoffJitterBounds(t *testing.T) {
+	client := &APIClient{}
+	for attempt := 1; attempt <= 5; attempt++ {
+		ceiling := client.retryBaseDelay() << (attempt - 1)
+		seen := make(map[time.Duration]bool)
+		for i := 0; i < 50; i++ {
+			d := client.backoff(attempt)
+			if d < 0 || d > ceiling {
+				t.Fatalf("attempt %d: backoff %v outside [0, %v]", attempt, d, ceiling)
+			}
+			seen[d] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("attempt %d: expected jittered delays, got a single value", attempt)
+		}
+	}
+}
+
+func TestDoWithRetryRecoversFromServerError(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient: &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		AccountID:  "test-account",
+		APIToken:   "test-token",
+	}
+	exists, err := client.EnsureSession(context.Background(), "retry-session")
+	if err != nil || !exists {
+		t.Fatalf("EnsureSession() = %v, %v; want true, nil", exists, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"empty", "", 0, false},
+		{"delta seconds", "5", 5 * time.Second, true},
+		{"negative seconds", "-1", 0, false},
+		{"http date", now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{"past http date", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.header, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDoWithRetryHonorsRetryAfter(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient: &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		AccountID:  "test-account",
+		APIToken:   "test-token",
+	}
+	start := time.Now()
+	if _, err := client.EnsureSession(context.Background(), "rate-limited"); err != nil {
+		t.Fatalf("EnsureSession() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait at least 1s for Retry-After, waited %v", elapsed)
+	}
+}
+
+// fakeClock is a controllable clock for testing.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestDoWithRetryRetryAfterDateUsesClock(t *testing.T) {
+	retryAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", retryAt.Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// The date is in the real past, so only the client's clock makes it a wait.
+	client := NewClient(
+		WithHTTPClient(&http.Client{Transport: &rewriteTransport{baseURL: srv.URL}}),
+		WithCredentials("test-account", "test-token", ""),
+		WithClock(&fakeClock{now: retryAt.Add(-time.Second)}),
+	)
+	client.RetryBaseDelay = time.Millisecond
+	start := time.Now()
+	if _, err := client.EnsureSession(context.Background(), "rate-limited"); err != nil {
+		t.Fatalf("EnsureSession() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait 1s until the Retry-After date, waited %v", elapsed)
+	}
+}
+
+func TestRetrySettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		client      *APIClient
+		wantRetries int
+		wantDelay   time.Duration
+	}{
+		{"defaults", &APIClient{}, maxRetries, retryBaseDelay},
+		{"custom", &APIClient{MaxRetries: 5, RetryBaseDelay: time.Second}, 5, time.Second},
+		{"disabled", &APIClient{MaxRetries: -1}, 0, retryBaseDelay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.client.maxRetries(); got != tt.wantRetries {
+				t.Errorf("maxRetries() = %d, want %d", got, tt.wantRetries)
+			}
+			if got := tt.client.retryBaseDelay(); got != tt.wantDelay {
+				t.Errorf("retryBaseDelay() = %v, want %v", got, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestNewClientFromEnvRetrySettings(t *testing.T) {
+	t.Setenv("CLOUDFLARE_MAX_RETRIES", "7")
+	t.Setenv("CLOUDFLARE_RETRY_BASE_DELAY", "250ms")
+
+	client := NewClientFromEnv().(*APIClient)
+	if client.MaxRetries != 7 {
+		t.Errorf("MaxRetries = %d, want 7", client.MaxRetries)
+	}
+	if client.RetryBaseDelay != 250*time.Millisecond {
+		t.Errorf("RetryBaseDelay = %v, want 250ms", client.RetryBaseDelay)
+	}
+}
+
+func TestNewClientOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer opt-token" {
+			t.Errorf("Authorization = %q, want bearer opt-token", got)
+		}
+		if !strings.Contains(r.URL.Path, "/accounts/opt-account/storage/kv/namespaces/opt-ns/") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte("10.0.0.1:8080"))
+	}))
+	defer srv.Close()
+
+	var logs []string
+	logger := funcr.New(func(prefix, args string) {
+		logs = append(logs, args)
+	}, funcr.Options{})
+
+	hc := &http.Client{}
+	client := NewClient(
+		WithHTTPClient(hc),
+		WithBaseURL(srv.URL),
+		WithCredentials("opt-account", "opt-token", "opt-ns"),
+		WithLogger(logger),
+	)
+	if client.HTTPClient != hc {
+		t.Error("WithHTTPClient did not replace the HTTP client")
+	}
+	client.DebugHTTP = true
+	endpoint, found, err := client.GetRoute(context.Background(), "opt-session")
+	if err != nil || !found || endpoint != "10.0.0.1:8080" {
+		t.Fatalf("GetRoute() = %q, %v, %v", endpoint, found, err)
+	}
+	if len(logs) == 0 {
+		t.Error("WithLogger logger was not used for a context without a logger")
+	}
+
+	if def := NewClient(); def.HTTPClient == nil || def.HTTPClient.Timeout != httpTimeout {
+		t.Errorf("NewClient() HTTP client = %+v, want default with %v timeout", def.HTTPClient, httpTimeout)
+	}
+}
+
+func TestNewClientFromEnvTransport(t *testing.T) {
+	client := NewClientFromEnv().(*APIClient)
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport = %T, want *http.Transport", client.HTTPClient.Transport)
+	}
+	if transport.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost || transport.MaxIdleConns != defaultMaxIdleConns {
+		t.Errorf("default pool = %d/%d, want %d/%d", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, defaultMaxIdleConns, defaultMaxIdleConnsPerHost)
+	}
+	if client.HTTPClient.Timeout != httpTimeout {
+		t.Errorf("Timeout = %v, want %v", client.HTTPClient.Timeout, httpTimeout)
+	}
+
+	t.Setenv("CLOUDFLARE_MAX_IDLE_CONNS", "200")
+	t.Setenv("CLOUDFLARE_MAX_IDLE_CONNS_PER_HOST", "64")
+	t.Setenv("CLOUDFLARE_IDLE_CONN_TIMEOUT", "45s")
+	transport = NewClientFromEnv().(*APIClient).HTTPClient.Transport.(*http.Transport)
+	if transport.MaxIdleConns != 200 || transport.MaxIdleConnsPerHost != 64 || transport.IdleConnTimeout != 45*time.Second {
+		t.Errorf("pool = %d/%d/%v, want 200/64/45s", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+}
+
+func TestListRoutesPaginates(t *testing.T) {
+	pages := map[string]string{
+		"":      `{"success":true,"errors":[],"result":[{"name":"session-a","expiration":1700000000},{"name":"session-b","metadata":{"pod":"p1"}}],"result_info":{"count":2,"cursor":"page2"}}`,
+		"page2": `{"success":true,"errors":[],"result":[{"name":"session-c"}],"result_info":{"count":1,"cursor":""}}`,
+	}
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET, got %s", r.Method)
+		}
+		if !strings.HasSuffix(r.URL.Path, "/storage/kv/namespaces/test-ns/keys") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		body, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		AccountID:   "test-account",
+		APIToken:    "test-token",
+		KVNamespace: "test-ns",
+	}
+	routes, err := client.ListRoutes(context.Background())
+	if err != nil {
+		t.Fatalf("ListRoutes() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 page requests, got %d", calls)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got %d", len(routes))
+	}
+	if routes[0].SessionID != "session-a" || !routes[0].Expiration.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected first route %+v", routes[0])
+	}
+	if routes[1].Metadata["pod"] != "p1" {
+		t.Errorf("expected metadata pod=p1, got %v", routes[1].Metadata)
+	}
+	if routes[2].SessionID != "session-c" || !routes[2].Expiration.IsZero() {
+		t.Errorf("unexpected last route %+v", routes[2])
+	}
+}
+
+func TestGzipResponses(t *testing.T) {
+	writeGzip := func(w http.ResponseWriter, status int, body string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(status)
+		zw := gzip.NewWriter(w)
+		_, _ = zw.Write([]byte(body))
+		_ = zw.Close()
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept-Encoding"); got != "gzip" {
+			t.Errorf("Accept-Encoding = %q, want gzip", got)
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/keys"):
+			writeGzip(w, http.StatusOK, `{"success":true,"errors":[],"result":[{"name":"session-a"}],"result_info":{"count":1,"cursor":""}}`)
+		case strings.Contains(r.URL.Path, "/values/plain"):
+			_, _ = w.Write([]byte("10.0.0.1:8080"))
+		default:
+			writeGzip(w, http.StatusForbidden, `{"success":false,"errors":[{"code":10000,"message":"Authentication error"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		AccountID:   "test-account",
+		APIToken:    "test-token",
+		KVNamespace: "test-ns",
+	}
+	routes, err := client.ListRoutes(context.Background())
+	if err != nil {
+		t.Fatalf("ListRoutes() error = %v", err)
+	}
+	if len(routes) != 1 || routes[0].SessionID != "session-a" {
+		t.Fatalf("ListRoutes() = %+v, want session-a", routes)
+	}
+
+	if endpoint, found, err := client.GetRoute(context.Background(), "plain"); err != nil || !found || endpoint != "10.0.0.1:8080" {
+		t.Fatalf("GetRoute() identity = %q, %v, %v", endpoint, found, err)
+	}
+
+	err = client.DeleteRoute(context.Background(), "denied")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Errors) == 0 || apiErr.Errors[0].Code != 10000 {
+		t.Fatalf("DeleteRoute() error = %v, want decoded gzip error envelope", err)
+	}
+}
+
+func TestListRoutesUnsuccessfulEnvelope(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":false,"errors":[{"code":10000,"message":"Authentication error"}],"result":null}`))
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		KVNamespace: "test-ns",
+	}
+	if _, err := client.ListRoutes(context.Background()); err == nil || !strings.Contains(err.Error(), "Authentication error") {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+}
+
+func TestEnsureRouteWritesVersionedValue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		metadata RouteMetadata
+		want     RouteValue
+		wantKeys []string
+	}{
+		{
+			name:     "without metadata",
+			want:     RouteValue{SchemaVersion: RouteSchemaVersion, Endpoint: "10.0.0.1:8080", SessionID: "valid-session", UpdatedAt: now},
+			wantKeys: []string{"endpoint", "schemaVersion", "sessionID", "updatedAt"},
+		},
+		{
+			name:     "with metadata",
+			metadata: RouteMetadata{TargetDeployment: "my-app"},
+			want: RouteValue{SchemaVersion: RouteSchemaVersion, Endpoint: "10.0.0.1:8080", SessionID: "valid-session", UpdatedAt: now,
+				Metadata: &RouteMetadata{TargetDeployment: "my-app"}},
+			wantKeys: []string{"endpoint", "metadata", "schemaVersion", "sessionID", "updatedAt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var contentType string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				contentType = r.Header.Get("Content-Type")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			client := NewClient(
+				WithHTTPClient(&http.Client{Transport: &rewriteTransport{baseURL: srv.URL}}),
+				WithCredentials("test-account", "test-token", "test-ns"),
+				WithClock(&fakeClock{now: now}),
+			)
+			if err := client.EnsureRouteWithMetadata(context.Background(), "valid-session", "10.0.0.1:8080", 0, tt.metadata); err != nil {
+				t.Fatalf("EnsureRouteWithMetadata() error = %v", err)
+			}
+			if contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+			var got RouteValue
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("decoding route value %q: %v", body, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("route value = %+v, want %+v", got, tt.want)
+			}
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(body, &raw); err != nil {
+				t.Fatalf("decoding route value %q: %v", body, err)
+			}
+			keys := make([]string, 0, len(raw))
+			for k := range raw {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("route value keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestGetRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		body         string
+		wantEndpoint string
+		wantFound    bool
+		wantErr      bool
+	}{
+		{"json value", http.StatusOK, `{"endpoint":"10.0.0.1:8080"}`, "10.0.0.1:8080", true, false},
+		{"plain value", http.StatusOK, "10.0.0.2:8080", "10.0.0.2:8080", true, false},
+		{"not found", http.StatusNotFound, "", "", false, false},
+		{"forbidden", http.StatusForbidden, "", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("expected GET, got %s", r.Method)
+				}
+				if !strings.HasSuffix(r.URL.Path, "/values/valid-session") {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client := &APIClient{
+				HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+				KVNamespace: "test-ns",
+			}
+			endpoint, found, err := client.GetRoute(context.Background(), "valid-session")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetRoute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if endpoint != tt.wantEndpoint || found != tt.wantFound {
+				t.Errorf("GetRoute() = %q, %v; want %q, %v", endpoint, found, tt.wantEndpoint, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestGetRouteValue(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want RouteValue
+	}{
+		{"legacy plain value", "10.0.0.2:8080", RouteValue{Endpoint: "10.0.0.2:8080"}},
+		{"unversioned json", `{"endpoint":"10.0.0.1:8080"}`, RouteValue{Endpoint: "10.0.0.1:8080"}},
+		{"current value", `{"schemaVersion":1,"endpoint":"10.0.0.3:8080","sessionID":"valid-session","metadata":{"targetDeployment":"web"}}`,
+			RouteValue{SchemaVersion: 1, Endpoint: "10.0.0.3:8080", SessionID: "valid-session", Metadata: &RouteMetadata{TargetDeployment: "web"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client := &APIClient{
+				HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+				KVNamespace: "test-ns",
+			}
+			got, found, err := client.GetRouteValue(context.Background(), "valid-session")
+			if err != nil || !found {
+				t.Fatalf("GetRouteValue() found = %v, error = %v", found, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRouteValue() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureRouteWithTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		wantQuery string
+	}{
+		{"no ttl", 0, ""},
+		{"ttl in seconds", 10 * time.Minute, "600"},
+		{"below KV minimum", 5 * time.Second, "60"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.Query().Get("expiration_ttl")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			client := &APIClient{
+				HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+				KVNamespace: "test-ns",
+			}
+			if err := client.EnsureRouteWithTTL(context.Background(), "valid-session", "10.0.0.1:8080", tt.ttl); err != nil {
+				t.Fatalf("EnsureRouteWithTTL() error = %v", err)
+			}
+			if got != tt.wantQuery {
+				t.Errorf("expiration_ttl = %q, want %q", got, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestDeleteRoutesBatchesAndReportsFailures(t *testing.T) {
+	ids := make([]string, bulkDeleteBatchSize+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("session-%d", i)
+	}
+
+	var batchSizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || !strings.HasSuffix(r.URL.Path, "/bulk") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var keys []string
+		if err := json.NewDecoder(r.Body).Decode(&keys); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		batchSizes = append(batchSizes, len(keys))
+		// Fail the second (partial) batch.
+		if len(keys) < bulkDeleteBatchSize {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		KVNamespace: "test-ns",
+	}
+	err := client.DeleteRoutes(context.Background(), append(ids, "bad/id"))
+	if err == nil {
+		t.Fatal("expected error for failed batch and invalid ID")
+	}
+	if len(batchSizes) != 2 || batchSizes[0] != bulkDeleteBatchSize || batchSizes[1] != 1 {
+		t.Errorf("batch sizes = %v, want [%d 1]", batchSizes, bulkDeleteBatchSize)
+	}
+	last := ids[len(ids)-1]
+	if !strings.Contains(err.Error(), last) || !strings.Contains(err.Error(), "bad/id") {
+		t.Errorf("error should list failed keys, got %v", err)
+	}
+	if strings.Contains(err.Error(), "session-0,") {
+		t.Errorf("error should not list keys from the successful batch")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *APIClient {
+		return &APIClient{AccountID: "acct", APIToken: "token", KVNamespace: "ns"}
+	}
+	tests := []struct {
+		name    string
+		mutate  func(c *APIClient)
+		wantErr string
+	}{
+		{"valid", func(c *APIClient) {}, ""},
+		{"missing account", func(c *APIClient) { c.AccountID = "" }, "AccountID"},
+		{"missing token", func(c *APIClient) { c.APIToken = "" }, "APIToken"},
+		{"missing namespace", func(c *APIClient) { c.KVNamespace = "" }, "KVNamespace"},
+		{"dry run without credentials", func(c *APIClient) { *c = APIClient{DryRun: true} }, ""},
+		{"relative base URL", func(c *APIClient) { c.BaseURL = "/client/v4" }, "BaseURL"},
+		{"unparseable base URL", func(c *APIClient) { c.BaseURL = "http://[::1" }, "BaseURL"},
+		{"custom base URL", func(c *APIClient) { c.BaseURL = "http://localhost:8787/client/v4" }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.mutate(c)
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want mention of %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClientFromEnvStrict(t *testing.T) {
+	t.Setenv("CLOUDFLARE_ACCOUNT_ID", "acct")
+	t.Setenv("CLOUDFLARE_API_TOKEN", "token")
+	t.Setenv("CLOUDFLARE_KV_NAMESPACE_ID", "")
+	t.Setenv("CLOUDFLARE_DRY_RUN", "")
+
+	if _, err := NewClientFromEnvStrict(); err == nil {
+		t.Fatal("expected error when CLOUDFLARE_KV_NAMESPACE_ID is missing")
+	}
+
+	t.Setenv("CLOUDFLARE_KV_NAMESPACE_ID", "ns")
+	t.Setenv("CLOUDFLARE_API_BASE_URL", "http://localhost:8787/client/v4/")
+	c, err := NewClientFromEnvStrict()
+	if err != nil {
+		t.Fatalf("NewClientFromEnvStrict() error = %v", err)
+	}
+	if got := c.apiBase(); got != "http://localhost:8787/client/v4" {
+		t.Errorf("apiBase() = %q", got)
+	}
+}
+
+func TestDoWithRetryPerAttemptTimeout(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// Hang until the client gives up on this attempt.
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient:        &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		RetryBaseDelay:    time.Millisecond,
+		PerRequestTimeout: 100 * time.Millisecond,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	exists, err := client.EnsureSession(ctx, "slow-session")
+	if err != nil || !exists {
+		t.Fatalf("EnsureSession() = %v, %v; want true, nil", exists, err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestParseRateLimitRemaining(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   float64
+		wantOK bool
+	}{
+		{"none", http.Header{}, 0, false},
+		{"legacy header", http.Header{"X-Ratelimit-Remaining": {"42"}}, 42, true},
+		{"draft remaining header", http.Header{"Ratelimit-Remaining": {"7"}}, 7, true},
+		{"structured header", http.Header{"Ratelimit": {`"default";r=50;t=30`}}, 50, true},
+		{"garbage", http.Header{"X-Ratelimit-Remaining": {"lots"}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRateLimitRemaining(tt.header)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRateLimitRemaining() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDoWithRetryRecordsRateLimitMetrics(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Ratelimit", `"default";r=12;t=30`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	before := testutil.ToFloat64(rateLimitedTotal)
+	client := &APIClient{
+		HTTPClient:     &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		RetryBaseDelay: time.Millisecond,
+	}
+	if _, err := client.EnsureSession(context.Background(), "metrics-session"); err != nil {
+		t.Fatalf("EnsureSession() error = %v", err)
+	}
+	if got := testutil.ToFloat64(rateLimitedTotal) - before; got != 1 {
+		t.Errorf("cloudflare_rate_limited_total increased by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(rateLimitRemaining); got != 12 {
+		t.Errorf("cloudflare_rate_limit_remaining = %v, want 12", got)
+	}
+}
+
+func TestDoWithRetryRecordsRetryMetrics(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method == http.MethodGet && calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient:     &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		MaxRetries:     2,
+		RetryBaseDelay: time.Millisecond,
+	}
+	successBefore := testutil.ToFloat64(requestRetriesTotal.WithLabelValues(http.MethodGet, retryOutcomeSuccess))
+	exhaustedBefore := testutil.ToFloat64(requestRetriesTotal.WithLabelValues(http.MethodDelete, retryOutcomeExhausted))
+
+	if _, err := client.EnsureSession(context.Background(), "retry-metrics"); err != nil {
+		t.Fatalf("EnsureSession() error = %v", err)
+	}
+	if err := client.DeleteRoute(context.Background(), "retry-metrics"); err == nil {
+		t.Fatal("DeleteRoute() error = nil, want error after exhausting retries")
+	}
+
+	if got := testutil.ToFloat64(requestRetriesTotal.WithLabelValues(http.MethodGet, retryOutcomeSuccess)) - successBefore; got != 1 {
+		t.Errorf("retries{GET,success} increased by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(requestRetriesTotal.WithLabelValues(http.MethodDelete, retryOutcomeExhausted)) - exhaustedBefore; got != 2 {
+		t.Errorf("retries{DELETE,exhausted} increased by %v, want 2", got)
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var calls int
+	healthy := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if !healthy {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	clk := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	client := &APIClient{
+		HTTPClient:       &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		MaxRetries:       -1,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+		Clock:            clk,
+	}
+	ctx := context.Background()
+
+	// Closed: failures reach Cloudflare until the threshold opens the breaker.
+	for i := 0; i < 2; i++ {
+		if _, err := client.EnsureSession(ctx, "breaker"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: error = %v, want server error", i, err)
+		}
+	}
+	// Open: fail fast without a request.
+	if _, err := client.EnsureSession(ctx, "breaker"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open breaker: error = %v, want ErrCircuitOpen", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls while open = %d, want 2", calls)
+	}
+
+	// Half-open: a failed probe reopens the breaker for another cooldown.
+	clk.now = clk.now.Add(time.Minute)
+	if _, err := client.EnsureSession(ctx, "breaker"); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("failed probe: error = %v, want server error", err)
+	}
+	if _, err := client.EnsureSession(ctx, "breaker"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after failed probe: error = %v, want ErrCircuitOpen", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls after failed probe = %d, want 3", calls)
+	}
+
+	// Half-open: a successful probe closes the breaker.
+	clk.now = clk.now.Add(time.Minute)
+	healthy = true
+	for i := 0; i < 2; i++ {
+		if _, err := client.EnsureSession(ctx, "breaker"); err != nil {
+			t.Fatalf("closed call %d: error = %v", i, err)
+		}
+	}
+	if calls != 5 {
+		t.Fatalf("calls after recovery = %d, want 5", calls)
+	}
+}
+
+func TestClientSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(prev)
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch {
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusForbidden)
+		case calls == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient:     &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		RetryBaseDelay: time.Millisecond,
+	}
+	if _, err := client.EnsureSession(context.Background(), "traced-session"); err != nil {
+		t.Fatalf("EnsureSession() error = %v", err)
+	}
+	if err := client.DeleteRoute(context.Background(), "traced-session"); err == nil {
+		t.Fatal("DeleteRoute() error = nil, want error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	attrs := func(s sdktrace.ReadOnlySpan) map[string]string {
+		m := map[string]string{}
+		for _, kv := range s.Attributes() {
+			m[string(kv.Key)] = kv.Value.Emit()
+		}
+		return m
+	}
+
+	ensure := attrs(spans[0])
+	if spans[0].Name() != "cloudflare.EnsureSession" || spans[0].InstrumentationScope().Name != tracerName {
+		t.Errorf("span = %q (scope %q)", spans[0].Name(), spans[0].InstrumentationScope().Name)
+	}
+	if got := ensure["cloudflare.session_id_hash"]; got == "" || strings.Contains(got, "traced-session") {
+		t.Errorf("session_id_hash = %q, want a hash", got)
+	}
+	if ensure["http.status_code"] != "200" || ensure["cloudflare.retries"] != "1" {
+		t.Errorf("EnsureSession attributes = %v, want status 200 and 1 retry", ensure)
+	}
+
+	if spans[1].Name() != "cloudflare.DeleteRoute" || spans[1].Status().Code != codes.Error {
+		t.Errorf("DeleteRoute span = %q status %v, want error", spans[1].Name(), spans[1].Status().Code)
+	}
+	if got := attrs(spans[1])["http.status_code"]; got != "403" {
+		t.Errorf("DeleteRoute http.status_code = %q, want 403", got)
+	}
+
+	// Operations without their own span must not annotate the caller's span.
+	recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	ctx, parent := otel.Tracer("caller").Start(context.Background(), "reconcile")
+	if _, _, err := client.GetRoute(ctx, "traced-session"); err != nil {
+		t.Fatalf("GetRoute() error = %v", err)
+	}
+	if err := client.Ping(ctx); err == nil {
+		t.Fatal("Ping() error = nil, want error for an unverified token")
+	}
+	parent.End()
+
+	spans = recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(spans))
+	}
+	for _, s := range spans[:2] {
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %q is not a child of the caller's span", s.Name())
+		}
+	}
+	if spans[0].Name() != "cloudflare.GetRoute" || attrs(spans[0])["http.status_code"] != "200" {
+		t.Errorf("GetRoute span = %q attributes %v", spans[0].Name(), attrs(spans[0]))
+	}
+	if spans[1].Name() != "cloudflare.Ping" {
+		t.Errorf("Ping span = %q", spans[1].Name())
+	}
+	if got := attrs(spans[2]); len(got) != 0 {
+		t.Errorf("caller span attributes = %v, want none", got)
+	}
+}
+
+func TestStructuredErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+		wantCode int
+	}{
+		{"auth failed", http.StatusForbidden, `{"success":false,"errors":[{"code":10000,"message":"Authentication error"}]}`, ErrAuthFailed, 10000},
+		{"rate limited", http.StatusTooManyRequests, "", ErrRateLimited, 0},
+		{"server error", http.StatusBadGateway, "<html>bad gateway</html>", ErrServer, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client := &APIClient{
+				HTTPClient:     &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+				KVNamespace:    "test-ns",
+				MaxRetries:     -1,
+				RetryBaseDelay: time.Millisecond,
+			}
+			err := client.EnsureRoute(context.Background(), "valid-session", "10.0.0.1:8080")
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("errors.Is(%v, %v) = false", err, tt.sentinel)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *APIError, got %T", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if tt.wantCode != 0 && (len(apiErr.Errors) == 0 || apiErr.Errors[0].Code != tt.wantCode) {
+				t.Errorf("Errors = %+v, want code %d", apiErr.Errors, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAPIErrorIncludesAllMessages(t *testing.T) {
+	const envelope = `{"success":false,"errors":[{"code":1000,"message":"invalid token"},{"code":1001,"message":"missing header"}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(envelope))
+	}))
+	defer srv.Close()
+
+	client := &APIClient{
+		HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		KVNamespace: "test-ns",
+	}
+	ctx := context.Background()
+	_, sessionErr := client.EnsureSession(ctx, "valid-session")
+	errs := map[string]error{
+		"EnsureSession": sessionErr,
+		"EnsureRoute":   client.EnsureRoute(ctx, "valid-session", "10.0.0.1:8080"),
+		"DeleteRoute":   client.DeleteRoute(ctx, "valid-session"),
+	}
+	for op, err := range errs {
+		if err == nil {
+			t.Errorf("%s: expected error", op)
+			continue
+		}
+		want := "cloudflare API error: [1000] invalid token; [1001] missing header"
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %q does not contain %q", op, err, want)
+		}
+	}
+}
+
+func TestDebugHTTPRedactsTokenAndPreservesBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"endpoint":"10.0.0.1:8080"}`))
+	}))
+	defer srv.Close()
+
+	var logs []string
+	logger := funcr.New(func(prefix, args string) {
+		logs = append(logs, args)
+	}, funcr.Options{})
+	ctx := logr.NewContext(context.Background(), logger)
+
+	client := &APIClient{
+		HTTPClient:  &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+		APIToken:    "super-secret-token",
+		KVNamespace: "test-ns",
+		DebugHTTP:   true,
+	}
+	endpoint, found, err := client.GetRoute(ctx, "valid-session")
+	if err != nil || !found || endpoint != "10.0.0.1:8080" {
+		t.Fatalf("GetRoute() = %q, %v, %v; body should survive debug logging", endpoint, found, err)
+	}
+
+	joined := strings.Join(logs, "\n")
+	if strings.Contains(joined, "super-secret-token") {
+		t.Fatalf("API token leaked into debug logs: %s", joined)
+	}
+	for _, want := range []string{"REDACTED", `"status"=200`, "10.0.0.1:8080"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("debug logs missing %q: %s", want, joined)
+		}
+	}
+}
+
+func TestTruncateBody(t *testing.T) {
+	if got := truncateBody([]byte("short"), 10); got != "short" {
+		t.Errorf("truncateBody(short) = %q", got)
+	}
+	if got := truncateBody([]byte("0123456789abc"), 10); got != "0123456789...(truncated)" {
+		t.Errorf("truncateBody(long) = %q", got)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	client := &APIClient{HTTPClient: &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}}}
+	if _, err := client.EnsureSession(context.Background(), "ua-session"); err != nil {
+		t.Fatalf("EnsureSession() error = %v", err)
+	}
+	if got != defaultUserAgent {
+		t.Errorf("default User-Agent = %q, want %q", got, defaultUserAgent)
+	}
+
+	client.UserAgent = UserAgentForVersion("v1.2.3")
+	if _, err := client.EnsureSession(context.Background(), "ua-session"); err != nil {
+		t.Fatalf("EnsureSession() error = %v", err)
+	}
+	if want := "cloudflare-session-operator/v1.2.3 " + runtime.Version(); got != want {
+		t.Errorf("User-Agent = %q, want %q", got, want)
+	}
+	if want := defaultUserAgent + " " + runtime.Version(); UserAgentForVersion("") != want {
+		t.Errorf("UserAgentForVersion(\"\") = %q, want %q", UserAgentForVersion(""), want)
+	}
+}
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantErr  bool
+		wantAuth bool
+	}{
+		{"active token", http.StatusOK, `{"success":true,"errors":[],"result":{"id":"t1","status":"active"}}`, false, false},
+		{"disabled token", http.StatusOK, `{"success":true,"errors":[],"result":{"id":"t1","status":"disabled"}}`, true, true},
+		{"unauthorized", http.StatusUnauthorized, `{"success":false,"errors":[{"code":1000,"message":"Invalid API Token"}]}`, true, true},
+		{"bad request", http.StatusBadRequest, "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/accounts/test-account/tokens/verify") {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client := &APIClient{
+				HTTPClient: &http.Client{Transport: &rewriteTransport{baseURL: srv.URL}},
+				AccountID:  "test-account",
+			}
+			err := client.Ping(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrAuthFailed) != tt.wantAuth {
+				t.Errorf("errors.Is(err, ErrAuthFailed) = %v, want %v (err: %v)", !tt.wantAuth, tt.wantAuth, err)
+			}
+		})
+	}
+}
+
+func TestHasCredentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *APIClient
+		want   bool
+	}{
+		{"complete", &APIClient{AccountID: "acct", APIToken: "tok", KVNamespace: "ns"}, true},
+		{"missing token", &APIClient{AccountID: "acct", KVNamespace: "ns"}, false},
+		{"missing namespace", &APIClient{AccountID: "acct", APIToken: "tok"}, false},
+		{"dry run", &APIClient{DryRun: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.client.HasCredentials(); got != tt.want {
+				t.Errorf("HasCredentials() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloneWithCredentials(t *testing.T) {
+	base := &APIClient{
+		HTTPClient:  &http.Client{},
+		AccountID:   "operator-acct",
+		APIToken:    "operator-token",
+		KVNamespace: "operator-ns",
+		BaseURL:     "https://cf.example.com/client/v4",
+		MaxRetries:  5,
+	}
+	tenant := base.CloneWithCredentials("tenant-acct", "tenant-token", "tenant-ns")
+	if tenant.AccountID != "tenant-acct" || tenant.APIToken != "tenant-token" || tenant.KVNamespace != "tenant-ns" {
+		t.Errorf("credentials = %q/%q/%q, want tenant values", tenant.AccountID, tenant.APIToken, tenant.KVNamespace)
+	}
+	if tenant.HTTPClient != base.HTTPClient || tenant.BaseURL != base.BaseURL || tenant.MaxRetries != 5 {
+		t.Error("CloneWithCredentials did not keep the base client's settings")
+	}
+	if base.AccountID != "operator-acct" {
+		t.Error("CloneWithCredentials modified the base client")
+	}
+}
@@ -0,0 +1,59 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// debugBodyLimit is the number of response body bytes logged in DebugHTTP mode.
+const debugBodyLimit = 1024
+
+// logRequest logs the outgoing request with credentials redacted.
+func (c *APIClient) logRequest(ctx context.Context, req *http.Request, attempt int) {
+	c.log(ctx).Info("cloudflare request",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"attempt", attempt,
+		"headers", redactHeaders(req.Header))
+}
+
+// logResponse logs the response status and the start of its body. The body is
+// restored so callers can still read it in full.
+func (c *APIClient) logResponse(ctx context.Context, req *http.Request, resp *http.Response) {
+	peek, err := io.ReadAll(io.LimitReader(resp.Body, debugBodyLimit+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+
+	keysAndValues := []any{
+		"method", req.Method,
+		"url", req.URL.String(),
+		"status", resp.StatusCode,
+		"body", truncateBody(peek, debugBodyLimit),
+	}
+	if err != nil {
+		keysAndValues = append(keysAndValues, "bodyError", err.Error())
+	}
+	c.log(ctx).Info("cloudflare response", keysAndValues...)
+}
+
+// redactHeaders copies h with the Authorization value replaced, so the API token
+// never reaches the logs.
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	if out.Get("Authorization") != "" {
+		out.Set("Authorization", "REDACTED")
+	}
+	return out
+}
+
+// truncateBody returns at most limit bytes of b as a string, marking truncation.
+func truncateBody(b []byte, limit int) string {
+	if len(b) <= limit {
+		return string(b)
+	}
+	return string(b[:limit]) + "...(truncated)"
+}
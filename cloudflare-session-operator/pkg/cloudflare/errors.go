@@ -0,0 +1,80 @@
+package cloudflare
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodyBytes bounds how much of an error response is read for details.
+const maxErrorBodyBytes = 64 << 10
+
+// Sentinel errors matched by *APIError via errors.Is, so callers can branch on
+// the failure class without inspecting status codes.
+var (
+	ErrAuthFailed  = errors.New("cloudflare authentication failed")
+	ErrRateLimited = errors.New("cloudflare rate limit exceeded")
+	ErrNotFound    = errors.New("cloudflare resource not found")
+	ErrServer      = errors.New("cloudflare server error")
+)
+
+// ErrCircuitOpen is returned without calling Cloudflare while the client's circuit
+// breaker is open after repeated failures. Callers should back off for longer
+// than they would for a single failed request.
+var ErrCircuitOpen = errors.New("cloudflare circuit breaker open")
+
+// ErrorDetail is a single entry from the "errors" array of a Cloudflare API response.
+type ErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// APIError is returned when Cloudflare rejects a request. It carries the HTTP
+// status and any error details from the response envelope.
+type APIError struct {
+	// Op names the failed operation, e.g. "KV write".
+	Op         string
+	StatusCode int
+	Errors     []ErrorDetail
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("cloudflare %s failed: status %d", e.Op, e.StatusCode)
+	if len(e.Errors) == 0 {
+		return msg
+	}
+	details := make([]string, len(e.Errors))
+	for i, d := range e.Errors {
+		details[i] = fmt.Sprintf("[%d] %s", d.Code, d.Message)
+	}
+	return msg + ": cloudflare API error: " + strings.Join(details, "; ")
+}
+
+// Is reports whether the error belongs to the class of target.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuthFailed:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// newAPIError builds an APIError from a failed response, decoding the Cloudflare
+// error envelope when the body contains one.
+func newAPIError(op string, resp *http.Response) *APIError {
+	apiErr := &APIError{Op: op, StatusCode: resp.StatusCode}
+	var envelope cfAPIResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodyBytes)).Decode(&envelope); err == nil {
+		apiErr.Errors = envelope.Errors
+	}
+	return apiErr
+}
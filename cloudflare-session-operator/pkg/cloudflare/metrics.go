@@ -0,0 +1,83 @@
+package cloudflare
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	rateLimitRemaining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cloudflare_rate_limit_remaining",
+		Help: "Requests remaining in the current Cloudflare API rate-limit window, as last reported by Cloudflare.",
+	})
+	rateLimitedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "cloudflare_rate_limited_total",
+		Help: "Total number of Cloudflare API responses with status 429.",
+	})
+	requestRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cloudflare_request_retries_total",
+		Help: "Total number of Cloudflare API request retries, by HTTP method and the operation's eventual outcome.",
+	}, []string{"method", "outcome"})
+	requestAttempts = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cloudflare_request_attempts",
+		Help:    "Number of attempts made per Cloudflare API operation, including the first.",
+		Buckets: []float64{1, 2, 3, 4, 6, 8},
+	}, []string{"method"})
+)
+
+// Outcomes for cloudflare_request_retries_total.
+const (
+	retryOutcomeSuccess   = "success"
+	retryOutcomeExhausted = "exhausted"
+)
+
+func init() {
+	metrics.Registry.MustRegister(rateLimitRemaining, rateLimitedTotal, requestRetriesTotal, requestAttempts)
+}
+
+// recordAttempts observes one operation that took attempts tries. Retries are
+// only counted when the first attempt did not settle the operation.
+func recordAttempts(method string, attempts int, outcome string) {
+	if attempts == 0 {
+		return
+	}
+	requestAttempts.WithLabelValues(method).Observe(float64(attempts))
+	if attempts > 1 {
+		requestRetriesTotal.WithLabelValues(method, outcome).Add(float64(attempts - 1))
+	}
+}
+
+// recordRateLimit updates the rate-limit metrics from a Cloudflare response.
+func recordRateLimit(resp *http.Response) {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		rateLimitedTotal.Inc()
+	}
+	if remaining, ok := parseRateLimitRemaining(resp.Header); ok {
+		rateLimitRemaining.Set(remaining)
+	}
+}
+
+// parseRateLimitRemaining extracts the remaining request quota. Cloudflare sends
+// the IETF draft "Ratelimit" header (e.g. `"default";r=50;t=30`); the legacy
+// X-RateLimit-Remaining and Ratelimit-Remaining forms are accepted too.
+func parseRateLimitRemaining(h http.Header) (float64, bool) {
+	for _, name := range []string{"X-RateLimit-Remaining", "Ratelimit-Remaining"} {
+		if v := strings.TrimSpace(h.Get(name)); v != "" {
+			if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+				return n, true
+			}
+		}
+	}
+	for _, param := range strings.Split(h.Get("Ratelimit"), ";") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(param), "r="); ok {
+			if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
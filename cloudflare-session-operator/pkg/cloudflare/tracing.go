@@ -0,0 +1,58 @@
+package cloudflare
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of spans around Cloudflare API calls.
+const tracerName = "cloudflare-client"
+
+// startSpan starts a client span for op as a child of any span in ctx. The
+// session ID is recorded only as a hash so traces never carry it verbatim, and
+// is omitted for operations that span many sessions.
+func startSpan(ctx context.Context, op, sessionID string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("cloudflare.operation", op)}
+	if sessionID != "" {
+		attrs = append(attrs, attribute.String("cloudflare.session_id_hash", hashSessionID(sessionID)))
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "cloudflare."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return context.WithValue(ctx, clientSpanKey{}, span), span
+}
+
+// clientSpanKey marks the span startSpan created so request-level attributes
+// are never written onto a caller's span.
+type clientSpanKey struct{}
+
+// clientSpan returns the span started by startSpan for ctx, or a no-op span
+// when the operation has none.
+func clientSpan(ctx context.Context) trace.Span {
+	if span, ok := ctx.Value(clientSpanKey{}).(trace.Span); ok {
+		return span
+	}
+	return trace.SpanFromContext(context.Background())
+}
+
+// endSpan marks the span failed when err is non-nil and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// hashSessionID returns a short, stable SHA-256 prefix of id for correlation.
+func hashSessionID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,59 @@
+// Package names normalizes identifiers into valid Kubernetes object names so the
+// reconciler, webhooks and other components agree on the exact same mapping.
+package names
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// MaxLabelLength is the RFC 1123 DNS label limit, which pod hostnames must satisfy.
+const MaxLabelLength = 63
+
+// UnknownSessionPodName is the pod name used when a session ID has no characters
+// usable in a pod name. The webhook rejects such IDs so two sessions never share it.
+const UnknownSessionPodName = "session-unknown"
+
+const sessionPodPrefix = "session-"
+
+// SanitizeLabel converts s into an RFC 1123 label: it is lowercased, underscores,
+// dots and any other invalid characters become hyphens, leading and trailing
+// hyphens are trimmed, and the result is truncated to MaxLabelLength without
+// leaving a trailing hyphen. It returns "" if s has no letters or digits.
+func SanitizeLabel(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	label := strings.Trim(b.String(), "-")
+	if len(label) > MaxLabelLength {
+		label = strings.TrimRight(label[:MaxLabelLength], "-")
+	}
+	return label
+}
+
+// SessionPodName returns the name of the dedicated pod for a session. IDs that
+// SanitizeLabel would alter (uppercase letters, underscores) or that are too long
+// get a short hash of the original ID appended so distinct sessions keep distinct
+// pods. IDs without letters or digits map to UnknownSessionPodName.
+func SessionPodName(sessionID string) string {
+	name := SanitizeLabel(sessionID)
+	if name == "" {
+		return UnknownSessionPodName
+	}
+	if name != sessionID || len(sessionPodPrefix)+len(name) > MaxLabelLength {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(sessionID))
+		suffix := fmt.Sprintf("-%08x", h.Sum32())
+		if limit := MaxLabelLength - len(sessionPodPrefix) - len(suffix); len(name) > limit {
+			name = strings.TrimRight(name[:limit], "-")
+		}
+		name += suffix
+	}
+	return sessionPodPrefix + name
+}
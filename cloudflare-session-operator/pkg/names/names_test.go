@@ -0,0 +1,65 @@
+package names
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "already valid", in: "abc-123", want: "abc-123"},
+		{name: "lowercased", in: "ABC", want: "abc"},
+		{name: "underscores and dots", in: "a_b.c", want: "a-b-c"},
+		{name: "other characters", in: "a b/c", want: "a-b-c"},
+		{name: "trims hyphens", in: "_abc_", want: "abc"},
+		{name: "no usable characters", in: "__..", want: ""},
+		{name: "empty", in: "", want: ""},
+		{name: "truncated", in: strings.Repeat("a", 70), want: strings.Repeat("a", 63)},
+		{name: "truncation drops trailing hyphen", in: strings.Repeat("a", 62) + "_b", want: strings.Repeat("a", 62)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeLabel(tt.in); got != tt.want {
+				t.Errorf("SanitizeLabel(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionPodName(t *testing.T) {
+	tests := []struct {
+		name      string
+		sessionID string
+		want      string
+	}{
+		{name: "valid ID unchanged", sessionID: "abc-123", want: "session-abc-123"},
+		{name: "no usable characters", sessionID: "___", want: UnknownSessionPodName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SessionPodName(tt.sessionID); got != tt.want {
+				t.Errorf("SessionPodName(%q) = %q, want %q", tt.sessionID, got, tt.want)
+			}
+		})
+	}
+
+	upper, lower := SessionPodName("Sess_A"), SessionPodName("sess-a")
+	if upper == lower {
+		t.Errorf("distinct session IDs mapped to the same pod name %q", upper)
+	}
+	if !strings.HasPrefix(upper, "session-sess-a-") {
+		t.Errorf("SessionPodName(%q) = %q, want session-sess-a-<hash>", "Sess_A", upper)
+	}
+
+	long := SessionPodName(strings.Repeat("a", 128))
+	if len(long) > MaxLabelLength {
+		t.Errorf("len(SessionPodName(long)) = %d, want <= %d", len(long), MaxLabelLength)
+	}
+	if long == SessionPodName(strings.Repeat("a", 127)) {
+		t.Error("truncated names collide")
+	}
+}
@@ -1,19 +1,27 @@
 package main
 
 import (
+	"container/list"
 	"context"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	flagd "github.com/open-feature/flagd-go-sdk/pkg/provider"
 	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
 )
 
 // Dynamic feature flags manager with OpenFeature (flagd) + optional admin overrides.
@@ -34,13 +42,23 @@ var (
 	tracerInitMu      sync.Mutex
 	tracerInitialized atomic.Bool
 	tracerShutdownFn  func(context.Context) error
+	// tracerInitInFlight is set while a background init runs. tracerInitErr
+	// (guarded by tracerInitMu) holds a failed init's error; it comes from the
+	// tracing configuration, so init is not attempted again until restart.
+	tracerInitInFlight atomic.Bool
+	tracerInitErr      error
+
+	featureFlagsInitialized atomic.Bool
+	// flagProviderConnected tracks flagd reachability from provider events;
+	// while false, evaluations fall back to the static defaults.
+	flagProviderConnected atomic.Bool
 )
 
 func initFeatureFlags(tracingDefault, metricsDefault bool) {
 	// Set defaults
 	defaultTracing.Store(tracingDefault)
 	defaultMetrics.Store(metricsDefault)
-	overridesValue.Store(flagOverrides{})
+	overridesValue.Store(loadOverrides(os.Getenv("ADMIN_FLAGS_STORE")))
 
 	// Initialize flagd provider if available, else noop
 	host := getenvDefault("FLAGD_HOST", "flagd")
@@ -52,8 +70,83 @@ func initFeatureFlags(tracingDefault, metricsDefault bool) {
 		flagd.WithMaxEventStreamRetries(3),
 		flagd.WithMaxProviderReadyWait(time.Second*3),
 	)
-	openfeature.SetProvider(provider)
+	// The client must exist before handlers can evaluate flags.
 	ofClient = openfeature.NewClient("hello-world")
+	onReady := func(openfeature.EventDetails) {
+		flagProviderConnected.Store(true)
+		applyRuntimeFlags(context.Background())
+	}
+	onChange := func(openfeature.EventDetails) { applyRuntimeFlags(context.Background()) }
+	onDown := func(openfeature.EventDetails) { flagProviderConnected.Store(false) }
+	openfeature.AddHandler(openfeature.ProviderReady, &onReady)
+	openfeature.AddHandler(openfeature.ProviderConfigChange, &onChange)
+	openfeature.AddHandler(openfeature.ProviderError, &onDown)
+	openfeature.AddHandler(openfeature.ProviderStale, &onDown)
+	openfeature.SetProvider(provider)
+	featureFlagsInitialized.Store(true)
+}
+
+// applyRuntimeFlags re-reads the flags that tune the running process rather
+// than individual requests.
+func applyRuntimeFlags(ctx context.Context) {
+	applyLogLevelFlag(ctx)
+	applySamplePercentFlag(ctx)
+}
+
+// loadOverrides reads admin overrides persisted at path. An empty path, a
+// missing file or unreadable contents all yield no overrides, so a bad store
+// never blocks startup.
+func loadOverrides(path string) flagOverrides {
+	if path == "" {
+		return flagOverrides{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn().Err(err).Str("path", path).Msg("cannot read flag overrides store, starting without overrides")
+		}
+		return flagOverrides{}
+	}
+	var ov flagOverrides
+	if err := json.Unmarshal(data, &ov); err != nil {
+		logger.Warn().Err(err).Str("path", path).Msg("corrupt flag overrides store, starting without overrides")
+		return flagOverrides{}
+	}
+	logger.Info().Str("path", path).Msg("loaded persisted flag overrides")
+	return ov
+}
+
+// persistOverrides writes ov to ADMIN_FLAGS_STORE when set. The file is
+// replaced via rename so a crash mid-write never leaves it truncated.
+// Failures are logged; the in-memory overrides still apply.
+func persistOverrides(ov flagOverrides) {
+	path := os.Getenv("ADMIN_FLAGS_STORE")
+	if path == "" {
+		return
+	}
+	if err := writeOverridesFile(path, ov); err != nil {
+		logger.Error().Err(err).Str("path", path).Msg("failed to persist flag overrides")
+	}
+}
+
+func writeOverridesFile(path string, ov flagOverrides) error {
+	data, err := json.Marshal(ov)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".flag-overrides-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 func getenvDefault(k, def string) string {
@@ -65,48 +158,127 @@ func getenvDefault(k, def string) string {
 
 func isTracingEnabled(ctx context.Context) bool {
 	ov := overridesValue.Load().(flagOverrides)
+	var val bool
 	if ov.Tracing != nil {
-		if *ov.Tracing {
-			ensureTracerProvider(ctx)
-		}
-		return *ov.Tracing
-	}
-	// Evaluate via OpenFeature with default
-	def := defaultTracing.Load()
-	val, err := ofClient.BooleanValue(ctx, "tracing_enabled", def, openfeature.EvaluationContext{})
-	if err != nil {
-		return def
+		val = *ov.Tracing
+	} else {
+		// Evaluate via OpenFeature with default
+		val = boolFlag(ctx, "tracing_enabled", defaultTracing.Load())
 	}
+	recordFlagEvaluation("tracing_enabled", strconv.FormatBool(val))
 	if val {
-		ensureTracerProvider(ctx)
+		ensureTracerProvider()
 	}
 	return val
 }
 
 func isMetricsEnabled(ctx context.Context) bool {
 	ov := overridesValue.Load().(flagOverrides)
+	var val bool
 	if ov.Metrics != nil {
-		return *ov.Metrics
+		val = *ov.Metrics
+	} else {
+		val = boolFlag(ctx, "metrics_enabled", defaultMetrics.Load())
 	}
-	def := defaultMetrics.Load()
-	val, err := ofClient.BooleanValue(ctx, "metrics_enabled", def, openfeature.EvaluationContext{})
+	recordFlagEvaluation("metrics_enabled", strconv.FormatBool(val))
+	return val
+}
+
+// Typed flag accessors backed by the OpenFeature client. Each returns def when
+// the provider is unavailable or the flag cannot be resolved.
+
+func boolFlag(ctx context.Context, key string, def bool) bool {
+	val, err := ofClient.BooleanValue(ctx, key, def, openfeature.EvaluationContext{})
 	if err != nil {
 		return def
 	}
 	return val
 }
 
+func stringFlag(ctx context.Context, key, def string) string {
+	val, err := ofClient.StringValue(ctx, key, def, openfeature.EvaluationContext{})
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+func intFlag(ctx context.Context, key string, def int64) int64 {
+	val, err := ofClient.IntValue(ctx, key, def, openfeature.EvaluationContext{})
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// recordFlagEvaluation counts a flag evaluation by key and resolved value.
+func recordFlagEvaluation(key, value string) {
+	if mtr != nil {
+		mtr.flagEvaluations.WithLabelValues(key, value).Inc()
+	}
+}
+
+// registeredFlags lists every flag the app knows about. Add new flags here to
+// expose them through GET /admin/flags.
+var registeredFlags = []struct {
+	name     string
+	key      string
+	def      *atomic.Bool
+	override func(flagOverrides) *bool
+}{
+	{name: "tracing", key: "tracing_enabled", def: &defaultTracing, override: func(o flagOverrides) *bool { return o.Tracing }},
+	{name: "metrics", key: "metrics_enabled", def: &defaultMetrics, override: func(o flagOverrides) *bool { return o.Metrics }},
+}
+
+// flagState is the default, override and effective value of a single flag.
+type flagState struct {
+	Default   bool  `json:"default"`
+	Override  *bool `json:"override"`
+	Effective bool  `json:"effective"`
+}
+
+// flagStates reports the state of every registered flag without side effects
+// (no tracer initialization, no evaluation metrics).
+func flagStates(ctx context.Context) map[string]flagState {
+	ov := overridesValue.Load().(flagOverrides)
+	states := make(map[string]flagState, len(registeredFlags))
+	for _, f := range registeredFlags {
+		st := flagState{Default: f.def.Load(), Override: f.override(ov)}
+		if st.Override != nil {
+			st.Effective = *st.Override
+		} else {
+			st.Effective = boolFlag(ctx, f.key, st.Default)
+		}
+		states[f.name] = st
+	}
+	return states
+}
+
 // Admin endpoints (enable with ADMIN_FLAGS_ENABLED=true)
-// GET /admin/flags -> current values and overrides
+// GET /admin/flags -> current values, overrides and per-flag {default, override, effective}
+// GET /admin/flags?flag=tracing -> state of a single flag
 // POST /admin/flags body: {"tracing": true/false, "metrics": true/false}
 // POST /admin/flags?tracing=true&metrics=false also supported
 // POST /admin/flags/reset -> clears overrides
+// PUT /admin/loglevel body: {"level": "debug"} -> changes the global log level
 //
-// Authentication: Requires X-Admin-API-Key header matching ADMIN_API_KEY env var
-// If ADMIN_API_KEY is not set, endpoints are INSECURE (dev/local only)
+// Authentication: Requires "Authorization: Bearer <key>" matching ADMIN_API_KEY env var
+// If ADMIN_API_KEY is not set, all admin requests are rejected (fail closed)
+// Browser access: ADMIN_CORS_ORIGINS lists origins allowed via CORS (see adminCORSMiddleware)
 
 func adminAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	limiter := getAdminRateLimiter()
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Rate limit before checking credentials to slow down brute-force attempts
+		if limiter != nil && !limiter.allow(clientIP(r)) {
+			logger.Warn().
+				Str("remote_addr", r.RemoteAddr).
+				Str("path", r.URL.Path).
+				Msg("admin endpoint rate limit exceeded")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+
 		apiKey := os.Getenv("ADMIN_API_KEY")
 
 		// Fail closed: if no API key is configured, reject all requests
@@ -119,38 +291,156 @@ func adminAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		// Check Authorization header (Bearer token) — constant-time comparison
-		authHeader := r.Header.Get("Authorization")
-		expected := "Bearer " + apiKey
-		if authHeader != "" && subtle.ConstantTimeCompare([]byte(authHeader), []byte(expected)) == 1 {
-			next(w, r)
-			return
-		}
-
-		// Check X-Admin-API-Key header — constant-time comparison
-		providedKey := r.Header.Get("X-Admin-API-Key")
-		if providedKey != "" && subtle.ConstantTimeCompare([]byte(providedKey), []byte(apiKey)) == 1 {
+		// Only "Authorization: Bearer <key>" is accepted; compared in constant time
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) == 1 {
 			next(w, r)
 			return
 		}
 
+		// Never log the attempted key
 		logger.Warn().
 			Str("remote_addr", r.RemoteAddr).
 			Str("path", r.URL.Path).
+			Bool("authorization_present", r.Header.Get("Authorization") != "").
 			Msg("unauthorized admin endpoint access attempt")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		w.WriteHeader(http.StatusUnauthorized)
 	}
 }
 
+// adminCORSMiddleware lets a browser UI on an allowlisted origin call the admin
+// endpoints. ADMIN_CORS_ORIGINS is a comma-separated list of exact origins; when
+// empty, CORS is disabled and next is returned unchanged. It must wrap
+// adminAuthMiddleware because preflights carry no Authorization header.
+func adminCORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	allowed := map[string]bool{}
+	for _, origin := range strings.Split(os.Getenv("ADMIN_CORS_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowed[origin] = true
+		}
+	}
+	if len(allowed) == 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		if !allowed[origin] {
+			next(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ipRateLimiter applies an independent token bucket per client IP.
+type ipRateLimiter struct {
+	mu    sync.Mutex
+	limit rate.Limit
+	burst int
+	// limiters indexes entries in lru, which is ordered most recently used first.
+	limiters map[string]*list.Element
+	lru      *list.List
+}
+
+type ipLimiterEntry struct {
+	ip  string
+	lim *rate.Limiter
+}
+
+// maxTrackedIPs bounds limiter memory; past this the least recently seen IP is
+// evicted, so rotating source IPs cannot reset the buckets of active clients.
+const maxTrackedIPs = 4096
+
+func newIPRateLimiter(rps float64) *ipRateLimiter {
+	return &ipRateLimiter{
+		limit:    rate.Limit(rps),
+		burst:    max(1, int(math.Ceil(rps*2))),
+		limiters: map[string]*list.Element{},
+		lru:      list.New(),
+	}
+}
+
+func (l *ipRateLimiter) allow(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.limiters[ip]; ok {
+		l.lru.MoveToFront(el)
+		return el.Value.(*ipLimiterEntry).lim.Allow()
+	}
+	if l.lru.Len() >= maxTrackedIPs {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		delete(l.limiters, oldest.Value.(*ipLimiterEntry).ip)
+	}
+	entry := &ipLimiterEntry{ip: ip, lim: rate.NewLimiter(l.limit, l.burst)}
+	l.limiters[ip] = l.lru.PushFront(entry)
+	return entry.lim.Allow()
+}
+
+var (
+	adminRateLimiterOnce sync.Once
+	adminRateLimiter     *ipRateLimiter
+)
+
+// getAdminRateLimiter returns the limiter shared by all admin endpoints, configured by
+// ADMIN_RATE_LIMIT in requests per second per IP (default 5; 0 disables limiting).
+func getAdminRateLimiter() *ipRateLimiter {
+	adminRateLimiterOnce.Do(func() {
+		rps := 5.0
+		if v := os.Getenv("ADMIN_RATE_LIMIT"); v != "" {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil || parsed < 0 || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+				logger.Warn().Str("value", v).Float64("default", rps).Msg("invalid ADMIN_RATE_LIMIT, using default")
+			} else {
+				rps = parsed
+			}
+		}
+		if rps > 0 {
+			adminRateLimiter = newIPRateLimiter(rps)
+		}
+	})
+	return adminRateLimiter
+}
+
+// clientIP returns the host part of the request's remote address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func adminFlagsHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
+		states := flagStates(r.Context())
+		// GET /admin/flags?flag=<name> returns a single flag's state
+		if name := r.URL.Query().Get("flag"); name != "" {
+			st, ok := states[name]
+			if !ok {
+				http.Error(w, "Not Found: unknown flag", http.StatusNotFound)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{name: st})
+			return
+		}
 		resp := map[string]any{
 			"defaults": map[string]bool{
 				"tracing": defaultTracing.Load(),
 				"metrics": defaultMetrics.Load(),
 			},
 			"overrides": overridesValue.Load().(flagOverrides),
+			"flags":     states,
 		}
 		writeJSON(w, http.StatusOK, resp)
 		return
@@ -172,6 +462,11 @@ func adminFlagsHandler(w http.ResponseWriter, r *http.Request) {
 		if ct := r.Header.Get("Content-Type"); ct == "application/json" || ct == "application/json; charset=utf-8" {
 			r.Body = http.MaxBytesReader(w, r.Body, 1024)
 			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+					return
+				}
 				http.Error(w, "Bad Request: invalid JSON", http.StatusBadRequest)
 				return
 			}
@@ -183,6 +478,10 @@ func adminFlagsHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		overridesValue.Store(ov)
+		persistOverrides(ov)
+		if ov.Tracing != nil && *ov.Tracing {
+			ensureTracerProvider()
+		}
 		writeJSON(w, http.StatusOK, map[string]any{"overrides": ov})
 		return
 	default:
@@ -197,28 +496,95 @@ func adminFlagsResetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	overridesValue.Store(flagOverrides{})
+	persistOverrides(flagOverrides{})
 	writeJSON(w, http.StatusOK, map[string]any{"overrides": overridesValue.Load()})
 }
 
+// adminLogLevelHandler reports (GET) or changes (PUT {"level":"debug"}) the global log level.
+func adminLogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var body struct {
+			Level string `json:"level"`
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, 1024)
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Bad Request: invalid JSON", http.StatusBadRequest)
+			return
+		}
+		lvl, err := zerolog.ParseLevel(body.Level)
+		if err != nil || body.Level == "" {
+			http.Error(w, "Bad Request: invalid log level", http.StatusBadRequest)
+			return
+		}
+		zerolog.SetGlobalLevel(lvl)
+		logger.Info().Str("level", lvl.String()).Msg("log level changed via admin endpoint")
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"level": zerolog.GlobalLevel().String()})
+}
+
 func writeJSON(w http.ResponseWriter, code int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	_ = json.NewEncoder(w).Encode(v)
 }
 
-func ensureTracerProvider(ctx context.Context) {
-	if tracerInitialized.Load() {
+// errorBody is the JSON error envelope returned to API consumers.
+type errorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// writeJSONError writes {"error":{"code":...,"message":...}}. code is a stable
+// machine-readable identifier; message is for humans and may change.
+func writeJSONError(w http.ResponseWriter, status int, code, message string) {
+	var body errorBody
+	body.Error.Code = code
+	body.Error.Message = message
+	writeJSON(w, status, body)
+}
+
+// ensureTracerProvider starts tracer provider initialization in the background
+// when tracing is enabled but no provider is installed. It never blocks: requests
+// served meanwhile are not traced.
+func ensureTracerProvider() {
+	if tracerInitialized.Load() || !tracerInitInFlight.CompareAndSwap(false, true) {
 		return
 	}
 	tracerInitMu.Lock()
-	defer tracerInitMu.Unlock()
-	if tracerInitialized.Load() {
+	failed := tracerInitErr != nil
+	tracerInitMu.Unlock()
+	if failed {
+		tracerInitInFlight.Store(false)
 		return
 	}
+	go func() {
+		defer tracerInitInFlight.Store(false)
+		initTracerProvider(context.Background())
+	}()
+}
 
+// initTracerProvider installs the tracer provider. Creating it never contacts
+// the collector; the OTLP exporters retry and reconnect on their own, so an
+// unreachable collector only delays span delivery. An error here means the
+// tracing configuration is invalid and is not retried.
+func initTracerProvider(ctx context.Context) {
+	if tracerInitialized.Load() {
+		return
+	}
 	shutdown, err := tracerProviderFactory(ctx)
+
+	tracerInitMu.Lock()
+	defer tracerInitMu.Unlock()
 	if err != nil {
-		logger.Warn().Err(err).Msg("tracing init failed, continuing without tracing")
+		tracerInitErr = err
+		logger.Error().Err(err).Msg("invalid tracing configuration, continuing without tracing")
 		return
 	}
 	tracerShutdownFn = shutdown
@@ -4,6 +4,7 @@ go 1.22
 
 require (
     github.com/golang-migrate/migrate/v4 v4.17.0
+    github.com/google/uuid v1.6.0
     github.com/lib/pq v1.10.9
     github.com/prometheus/client_golang v1.17.0
     github.com/open-feature/flagd-go-sdk v0.12.0
@@ -11,7 +12,11 @@ require (
     github.com/rs/zerolog v1.33.0
     go.opentelemetry.io/otel v1.38.0
     go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.38.0
+    go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.38.0
     go.opentelemetry.io/otel/sdk v1.38.0
+    golang.org/x/net v0.43.0
+    golang.org/x/time v0.5.0
+    gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
 
 require (
@@ -22,7 +27,6 @@ require (
         github.com/go-logr/logr v1.4.3 // indirect
         github.com/go-logr/stdr v1.2.2 // indirect
         github.com/golang/protobuf v1.5.4 // indirect
-        github.com/google/uuid v1.6.0 // indirect
         github.com/grpc-ecosystem/grpc-gateway/v2 v2.27.2 // indirect
         github.com/hashicorp/errwrap v1.1.0 // indirect
         github.com/hashicorp/go-multierror v1.1.1 // indirect
@@ -35,7 +39,6 @@ require (
         go.opentelemetry.io/otel/metric v1.38.0 // indirect
         go.opentelemetry.io/proto/otlp v1.7.1 // indirect
         go.uber.org/atomic v1.7.0 // indirect
-        golang.org/x/net v0.43.0 // indirect
         golang.org/x/sys v0.35.0 // indirect
         golang.org/x/text v0.28.0 // indirect
         google.golang.org/genproto/googleapis/api v0.0.0-20250825161204-c5933d9347a5 // indirect
@@ -2,25 +2,53 @@ package main
 
 import (
 	"context"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var logger zerolog.Logger
 
+// The effective log level is zerolog's atomic global level. It starts at LOG_LEVEL and
+// can change at runtime via PUT /admin/loglevel or the "log.level" feature flag;
+// the flag is re-read on provider ready and config-change events and applied
+// whenever its resolved value changes (last writer wins).
+var lastLogLevelFlag atomic.Value // string
+
+// logSpanEvents controls whether warn/error logs are also added as events on the
+// active span (LOG_SPAN_EVENTS, default true). Only applies while tracing is recording.
+var logSpanEvents = true
+
+// logBaggageKeys lists the OpenTelemetry baggage members (LOG_BAGGAGE_KEYS,
+// comma-separated, e.g. "tenant.id") copied onto request log lines as fields.
+var logBaggageKeys []string
+
 func initLogger() {
 	// Configure output format based on environment
 	zerolog.TimeFieldFormat = time.RFC3339Nano
-	output := os.Stdout
+	var output io.Writer = os.Stdout
 
-	// Development mode: pretty console output
-	// Production mode: JSON
+	// Development mode: pretty console output (always stdout)
+	// Production mode: JSON to stdout, or to a size-rotated LOG_FILE when set
 	logFormat := os.Getenv("LOG_FORMAT")
 	if logFormat == "pretty" || logFormat == "console" {
 		output = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	} else if path := os.Getenv("LOG_FILE"); path != "" {
+		output = &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    envInt("LOG_MAX_SIZE_MB", 100),
+			MaxBackups: envInt("LOG_MAX_BACKUPS", 5),
+			MaxAge:     envInt("LOG_MAX_AGE_DAYS", 28),
+		}
 	}
 
 	// Set log level from environment (default: info)
@@ -33,19 +61,53 @@ func initLogger() {
 		}
 	}
 
+	zerolog.SetGlobalLevel(level)
+	logSpanEvents = getBoolEnv("LOG_SPAN_EVENTS", true)
+	logBaggageKeys = nil
+	for _, key := range strings.Split(os.Getenv("LOG_BAGGAGE_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			logBaggageKeys = append(logBaggageKeys, key)
+		}
+	}
 	logger = zerolog.New(output).
-		Level(level).
 		With().
 		Timestamp().
 		Str("service", "hello-world").
 		Str("version", version).
 		Logger()
+
+	// Optional sampling for high-volume paths: LOG_SAMPLE_EVERY_N=N keeps 1 in N
+	// debug/info lines; warn and above are never sampled.
+	if v := os.Getenv("LOG_SAMPLE_EVERY_N"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			logger.Warn().Str("value", v).Msg("invalid LOG_SAMPLE_EVERY_N, logging every line")
+		} else if n > 1 {
+			sampler := &zerolog.BasicSampler{N: uint32(n)}
+			logger = logger.Sample(zerolog.LevelSampler{DebugSampler: sampler, InfoSampler: sampler})
+		}
+	}
+}
+
+// envInt reads a non-negative integer env var for logger setup. Unlike getIntEnv it
+// cannot log parse failures because the logger is not configured yet.
+func envInt(name string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
 }
 
-// loggerFromContext returns a logger enriched with trace ID if present
+// loggerFromContext returns a logger enriched with request and trace IDs and any
+// LOG_BAGGAGE_KEYS baggage members if present
 func loggerFromContext(ctx context.Context) *zerolog.Logger {
 	l := logger.With().Logger()
 
+	if id := requestIDFromContext(ctx); id != "" {
+		l = l.With().Str("request_id", id).Logger()
+	}
+
 	// Extract and add trace ID if present
 	sc := trace.SpanContextFromContext(ctx)
 	if sc.IsValid() {
@@ -55,5 +117,49 @@ func loggerFromContext(ctx context.Context) *zerolog.Logger {
 			Logger()
 	}
 
+	// Copy selected upstream baggage (e.g. tenant.id) so it threads through all logs
+	if len(logBaggageKeys) > 0 {
+		bag := baggage.FromContext(ctx)
+		for _, key := range logBaggageKeys {
+			if m := bag.Member(key); m.Key() != "" {
+				l = l.With().Str(key, m.Value()).Logger()
+			}
+		}
+	}
+
+	// Mirror warn/error lines onto the active span so traces carry the same context
+	if logSpanEvents {
+		if span := trace.SpanFromContext(ctx); span.IsRecording() {
+			l = l.Hook(spanEventHook{span: span})
+		}
+	}
+
 	return &l
 }
+
+// applyLogLevelFlag updates the global log level when the "log.level" flag changes.
+// It runs from flag provider events rather than per log call.
+func applyLogLevelFlag(ctx context.Context) {
+	v := stringFlag(ctx, "log.level", "")
+	if v == "" {
+		return
+	}
+	if prev, _ := lastLogLevelFlag.Swap(v).(string); prev == v {
+		return
+	}
+	if lvl, err := zerolog.ParseLevel(v); err == nil {
+		zerolog.SetGlobalLevel(lvl)
+	}
+}
+
+// spanEventHook records warn and error log messages as events on a span.
+type spanEventHook struct {
+	span trace.Span
+}
+
+func (h spanEventHook) Run(_ *zerolog.Event, level zerolog.Level, msg string) {
+	if level < zerolog.WarnLevel || level == zerolog.NoLevel {
+		return
+	}
+	h.span.AddEvent(msg, trace.WithAttributes(attribute.String("log.severity", level.String())))
+}
@@ -3,73 +3,248 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	migrate "github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
-// version is injected at build time via -ldflags "-X main.version=<version>"
-var version = "dev"
+// version and buildTime are injected at build time via
+// -ldflags "-X main.version=<version> -X main.buildTime=<RFC 3339 timestamp>"
+var (
+	version   = "dev"
+	buildTime = "unknown"
+)
 
 type appMetrics struct {
-	reqCount    *prometheus.CounterVec
-	reqDuration *prometheus.HistogramVec
+	reqCount         *prometheus.CounterVec
+	reqDuration      *prometheus.HistogramVec
+	reqInFlight      *prometheus.GaugeVec
+	panics           prometheus.Counter
+	dbDuration       *prometheus.HistogramVec
+	flagEvaluations  *prometheus.CounterVec
+	migrationVersion prometheus.Gauge
+	migrationDirty   prometheus.Gauge
 }
 
 var (
 	mtr *appMetrics
+
+	// migrationsComplete gates readiness until migrations have run or been
+	// deliberately skipped, so traffic never reaches a pod mid-migration.
+	migrationsComplete atomic.Bool
 )
 
+// instrumentedDB wraps *sql.DB and records the latency of each operation
+// in db_operation_duration_seconds, labeled by operation name.
+type instrumentedDB struct {
+	*sql.DB
+}
+
+func newInstrumentedDB(db *sql.DB) *instrumentedDB {
+	if db == nil {
+		return nil
+	}
+	return &instrumentedDB{DB: db}
+}
+
+func (d *instrumentedDB) observe(op string, start time.Time) {
+	if mtr != nil {
+		mtr.dbDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (d *instrumentedDB) PingContext(ctx context.Context) error {
+	defer d.observe("ping", time.Now())
+	return d.DB.PingContext(ctx)
+}
+
+func (d *instrumentedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer d.observe("exec", time.Now())
+	return d.DB.ExecContext(ctx, query, args...)
+}
+
+func (d *instrumentedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer d.observe("query", time.Now())
+	return d.DB.QueryContext(ctx, query, args...)
+}
+
 type dependencyChecker struct {
-	db *sql.DB
+	// db is filled in once the database is connected, after the listener has
+	// started; nil (or an empty pointer) means no database yet or none configured.
+	db *atomic.Pointer[instrumentedDB]
+	// query, when set (READINESS_DB_QUERY), is run after the ping to confirm
+	// the schema is usable and not merely that the server accepts connections.
+	query string
 }
 
-func (c dependencyChecker) pingDatabase(ctx context.Context) error {
+// database returns the connected database, or nil if there is none yet.
+func (c dependencyChecker) database() *instrumentedDB {
 	if c.db == nil {
 		return nil
 	}
+	return c.db.Load()
+}
+
+func (c dependencyChecker) pingDatabase(ctx context.Context) error {
+	db := c.database()
+	if db == nil {
+		return nil
+	}
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
-	if err := c.db.PingContext(ctx); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return fmt.Errorf("database ping: %w", err)
 	}
+	if c.query == "" {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx, c.query)
+	if err != nil {
+		return fmt.Errorf("database readiness query: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("database readiness query: %w", err)
+	}
+	return nil
+}
+
+// poolWarnings reports connection pool saturation. A saturated pool does not
+// fail readiness, but requests will queue waiting for a free connection.
+func (c dependencyChecker) poolWarnings() []string {
+	db := c.database()
+	if db == nil {
+		return nil
+	}
+	stats := db.Stats()
+	if stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections {
+		return []string{fmt.Sprintf("database connection pool saturated: %d/%d in use", stats.InUse, stats.MaxOpenConnections)}
+	}
 	return nil
 }
 
 func (c dependencyChecker) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if !migrationsComplete.Load() {
+		writeJSONError(w, http.StatusServiceUnavailable, "not_ready", "migrations in progress")
+		return
+	}
 	if err := c.pingDatabase(r.Context()); err != nil {
 		logger.Warn().Err(err).Msg("readiness check failed")
-		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "not_ready", "database unavailable")
+		return
+	}
+	warnings := c.poolWarnings()
+	if c.flagProviderStatus() == "degraded" {
+		warnings = append(warnings, "feature flag provider disconnected, serving default flag values")
+	}
+	if len(warnings) > 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ready", "warnings": warnings})
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte("ready"))
 }
 
+// flagProviderStatus reports "disabled" when feature flags were never
+// initialized, "degraded" when flagd is unreachable and defaults are served,
+// and "ok" otherwise. It never fails readiness.
+func (c dependencyChecker) flagProviderStatus() string {
+	switch {
+	case !featureFlagsInitialized.Load():
+		return "disabled"
+	case !flagProviderConnected.Load():
+		return "degraded"
+	default:
+		return "ok"
+	}
+}
+
+// healthCheck is the outcome of a single subsystem check reported by /healthz.
+type healthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthzHandler aggregates all subsystem checks into a single JSON report.
+// Only the database is required; tracing and feature flags are informational.
+func (c dependencyChecker) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	status, code := "ok", http.StatusOK
+	checks := map[string]healthCheck{}
+
+	if c.database() == nil {
+		checks["database"] = healthCheck{Status: "disabled"}
+	} else if err := c.pingDatabase(r.Context()); err != nil {
+		checks["database"] = healthCheck{Status: "error", Error: err.Error()}
+		status, code = "error", http.StatusServiceUnavailable
+	} else {
+		checks["database"] = healthCheck{Status: "ok"}
+	}
+
+	if tracerInitialized.Load() {
+		checks["tracing"] = healthCheck{Status: "ok"}
+	} else {
+		checks["tracing"] = healthCheck{Status: "disabled"}
+	}
+
+	checks["feature_flags"] = healthCheck{Status: c.flagProviderStatus()}
+
+	writeJSON(w, code, map[string]any{"status": status, "checks": checks})
+}
+
 // securityHeaders adds standard HTTP security headers to all responses.
+// CSP_HEADER and FRAME_OPTIONS override the Content-Security-Policy and
+// X-Frame-Options values; setting CSP_HEADER to an empty string omits that header.
 func securityHeaders(next http.Handler) http.Handler {
+	csp, ok := os.LookupEnv("CSP_HEADER")
+	if !ok {
+		csp = "default-src 'none'"
+	}
+	frameOptions := getenvDefault("FRAME_OPTIONS", "DENY")
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-Content-Type-Options", "nosniff")
-		w.Header().Set("X-Frame-Options", "DENY")
-		w.Header().Set("Content-Security-Policy", "default-src 'none'")
+		w.Header().Set("X-Frame-Options", frameOptions)
+		if csp != "" {
+			w.Header().Set("Content-Security-Policy", csp)
+		}
 		w.Header().Set("Referrer-Policy", "no-referrer")
 		w.Header().Set("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
 		next.ServeHTTP(w, r)
@@ -84,7 +259,9 @@ func (c dependencyChecker) livenessHandler(w http.ResponseWriter, r *http.Reques
 	_, _ = w.Write([]byte("alive"))
 }
 
-func enableMetrics() *appMetrics {
+// enableMetrics registers the application collectors on a dedicated registry.
+// Go runtime and process collectors are added only when INCLUDE_GO_COLLECTORS is true.
+func enableMetrics() (*appMetrics, *prometheus.Registry) {
 	mc := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "http_requests_total",
@@ -94,13 +271,101 @@ func enableMetrics() *appMetrics {
 	)
 	mh := prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name: "http_request_duration_seconds",
-			Help: "Histogram of latencies for HTTP requests.",
+			Name:    "http_request_duration_seconds",
+			Help:    "Histogram of latencies for HTTP requests.",
+			Buckets: durationBuckets(),
 		},
 		[]string{"handler", "method"},
 	)
-	prometheus.MustRegister(mc, mh)
-	return &appMetrics{reqCount: mc, reqDuration: mh}
+	mf := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests currently being served, labeled by handler.",
+		},
+		[]string{"handler"},
+	)
+	mp := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "http_panics_total",
+		Help: "Count of panics recovered from HTTP handlers.",
+	})
+	mdb := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "db_operation_duration_seconds",
+			Help: "Histogram of database operation latencies, labeled by operation.",
+		},
+		[]string{"operation"},
+	)
+	mfe := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "feature_flag_evaluations_total",
+			Help: "Count of feature flag evaluations, labeled by flag key and resolved value.",
+		},
+		[]string{"flag", "value"},
+	)
+	mv := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "app_migration_version",
+		Help: "Current database schema migration version.",
+	})
+	md := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "app_migration_dirty",
+		Help: "1 if the last migration left the schema in a dirty state, 0 otherwise.",
+	})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(mc, mh, mf, mp, mdb, mfe, mv, md)
+	if getBoolEnv("INCLUDE_GO_COLLECTORS", false) {
+		reg.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	}
+	return &appMetrics{
+		reqCount:         mc,
+		reqDuration:      mh,
+		reqInFlight:      mf,
+		panics:           mp,
+		dbDuration:       mdb,
+		flagEvaluations:  mfe,
+		migrationVersion: mv,
+		migrationDirty:   md,
+	}, reg
+}
+
+// durationBuckets returns the latency histogram buckets from HTTP_DURATION_BUCKETS
+// (comma-separated seconds), falling back to prometheus.DefBuckets when unset or invalid.
+func durationBuckets() []float64 {
+	v := strings.TrimSpace(os.Getenv("HTTP_DURATION_BUCKETS"))
+	if v == "" {
+		return prometheus.DefBuckets
+	}
+	buckets, err := parseBuckets(v)
+	if err != nil {
+		logger.Warn().Err(err).Str("value", v).Msg("invalid HTTP_DURATION_BUCKETS, using defaults")
+		return prometheus.DefBuckets
+	}
+	return buckets
+}
+
+// parseBuckets parses comma-separated positive floats into sorted, strictly increasing buckets.
+func parseBuckets(s string) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	buckets := make([]float64, 0, len(parts))
+	for _, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse bucket %q: %w", p, err)
+		}
+		if f <= 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("bucket %q must be a positive finite number", p)
+		}
+		buckets = append(buckets, f)
+	}
+	sort.Float64s(buckets)
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] == buckets[i-1] {
+			return nil, fmt.Errorf("duplicate bucket %v", buckets[i])
+		}
+	}
+	return buckets, nil
 }
 
 func getBoolEnv(name string, def bool) bool {
@@ -118,41 +383,196 @@ func getBoolEnv(name string, def bool) bool {
 	}
 }
 
+// getDurationEnv parses a Go duration (e.g. "30s") from the named env var.
+// Unset values return def; invalid values log a warning and return def.
+func getDurationEnv(name string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		logger.Warn().Str("env", name).Str("value", v).Dur("default", def).Msg("invalid duration, using default")
+		return def
+	}
+	return d
+}
+
+// getIntEnv parses a non-negative integer from the named env var.
+// Unset values return def; invalid values log a warning and return def.
+func getIntEnv(name string, def int) int {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		logger.Warn().Str("env", name).Str("value", v).Int("default", def).Msg("invalid integer, using default")
+		return def
+	}
+	return n
+}
+
+// versionInfo is the body served by /version.
+type versionInfo struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	BuildTime string `json:"build_time"`
+}
+
+// versionHandler reports the running build for monitoring and canary tooling.
+// It is unauthenticated since none of this is sensitive.
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSONError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, versionInfo{
+		Version:   version,
+		GoVersion: runtime.Version(),
+		BuildTime: buildTime,
+	})
+}
+
 func helloHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	rec := newStatusRecorder(w)
 	// Dynamic tracing flag (OpenFeature override-able)
 	if isTracingEnabled(ctx) {
 		var span trace.Span
 		ctx, span = otel.Tracer("hello-world").Start(ctx, "helloHandler")
 		defer span.End()
+		defer func() { setSpanHTTPAttributes(span, r, rec.status) }()
 	}
 
 	start := time.Now()
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte("hello world"))
+	rec.WriteHeader(http.StatusOK)
+	_, _ = rec.Write([]byte("hello world"))
 	dur := time.Since(start).Seconds()
-	if isMetricsEnabled(ctx) && mtr != nil {
-		mtr.reqCount.WithLabelValues("/", r.Method, "200").Inc()
-		mtr.reqDuration.WithLabelValues("/", r.Method).Observe(dur)
-	}
 
 	loggerFromContext(ctx).Info().
 		Str("method", r.Method).
 		Str("path", r.URL.Path).
 		Str("remote_addr", r.RemoteAddr).
 		Str("user_agent", r.UserAgent()).
-		Int("status", http.StatusOK).
+		Int("status", rec.status).
 		Float64("duration_seconds", dur).
 		Msg("handled request")
 }
 
+// newTraceExporter creates the OTLP exporter selected by OTEL_EXPORTER_OTLP_PROTOCOL:
+// "grpc" (collector port 4317) or "http/protobuf" (port 4318, the default).
+// Both honor OTEL_EXPORTER_OTLP_ENDPOINT if set.
+func newTraceExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch protocol := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))); protocol {
+	case "", "http", "http/protobuf":
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp http exporter: %w", err)
+		}
+		return exp, nil
+	case "grpc":
+		exp, err := otlptracegrpc.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp grpc exporter: %w", err)
+		}
+		return exp, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_EXPORTER_OTLP_PROTOCOL %q (expected grpc or http/protobuf)", protocol)
+	}
+}
+
+// traceSampleRatio reads OTEL_TRACES_SAMPLER_ARG as a ratio clamped to [0,1], defaulting to 1.0.
+func traceSampleRatio() float64 {
+	v := strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER_ARG"))
+	if v == "" {
+		return 1.0
+	}
+	ratio, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(ratio) {
+		logger.Warn().Str("value", v).Msg("invalid OTEL_TRACES_SAMPLER_ARG, sampling all traces")
+		return 1.0
+	}
+	return math.Max(0, math.Min(1, ratio))
+}
+
+// sampleRatioBits holds the root-span sampling ratio as float64 bits. It is
+// OTEL_TRACES_SAMPLER_ARG unless the "tracing.sample_percent" flag overrides it.
+var sampleRatioBits atomic.Uint64
+
+// applySamplePercentFlag sets the sampling ratio from the "tracing.sample_percent"
+// flag (0-100), falling back to OTEL_TRACES_SAMPLER_ARG while the flag is unset.
+// Like the log.level flag, it runs from flag provider events.
+func applySamplePercentFlag(ctx context.Context) {
+	ratio := traceSampleRatio()
+	if pct := intFlag(ctx, "tracing.sample_percent", -1); pct >= 0 {
+		ratio = float64(min(pct, 100)) / 100
+	}
+	sampleRatioBits.Store(math.Float64bits(ratio))
+}
+
+// flagRatioSampler samples root spans by trace ID at the current sampleRatioBits
+// ratio, so sampling can change without rebuilding the tracer provider.
+type flagRatioSampler struct{}
+
+func (flagRatioSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return sdktrace.TraceIDRatioBased(math.Float64frombits(sampleRatioBits.Load())).ShouldSample(p)
+}
+
+func (flagRatioSampler) Description() string { return "FlagRatioSampler" }
+
+// setSpanHTTPAttributes annotates span with standard HTTP attributes and marks
+// 5xx responses as errors.
+func setSpanHTTPAttributes(span trace.Span, r *http.Request, status int) {
+	route := routeFromContext(r.Context())
+	if route == "" {
+		route = r.URL.Path
+	}
+	span.SetAttributes(
+		attribute.String("http.method", r.Method),
+		attribute.String("http.route", route),
+		attribute.Int("http.status_code", status),
+		attribute.String("http.user_agent", r.UserAgent()),
+	)
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
+
 func initTracer(ctx context.Context) (func(context.Context) error, error) {
-	// Uses OTEL_EXPORTER_OTLP_ENDPOINT (e.g., http://otel-collector:4318) if set
-	exp, err := otlptracehttp.New(ctx)
+	exp, err := newTraceExporter(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("create otlp http exporter: %w", err)
+		return nil, err
 	}
 
+	res, err := newTraceResource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// ParentBased honors the sampling decision of upstream callers
+	applySamplePercentFlag(ctx)
+	counted := &countingExporter{SpanExporter: exp}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(counted),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(flagRatioSampler{})),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagator)
+	return func(ctx context.Context) error {
+		before := counted.exported.Load()
+		err := tp.Shutdown(ctx)
+		logger.Info().Int64("spans_flushed", counted.exported.Load()-before).Msg("tracer provider shut down")
+		return err
+	}, nil
+}
+
+// newTraceResource describes this service on every span. OTEL_RESOURCE_ATTRIBUTES
+// (key1=val1,key2=val2) adds deployment tags such as team or region and, like
+// OTEL_SERVICE_NAME, overrides the built-in attributes when keys collide.
+func newTraceResource(ctx context.Context) (*resource.Resource, error) {
 	svcName := os.Getenv("OTEL_SERVICE_NAME")
 	if svcName == "" {
 		svcName = "hello-world"
@@ -164,17 +584,28 @@ func initTracer(ctx context.Context) (func(context.Context) error, error) {
 			attribute.String("service.version", version),
 			attribute.String("env", os.Getenv("ENVIRONMENT")),
 		),
+		// Applied last so environment-supplied values win
+		resource.WithFromEnv(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("create resource: %w", err)
 	}
+	return res, nil
+}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exp),
-		sdktrace.WithResource(res),
-	)
-	otel.SetTracerProvider(tp)
-	return tp.Shutdown, nil
+// countingExporter tallies spans accepted by the wrapped exporter. The SDK does
+// not report how many queued spans a shutdown flushed, so this does.
+type countingExporter struct {
+	sdktrace.SpanExporter
+	exported atomic.Int64
+}
+
+func (e *countingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err == nil {
+		e.exported.Add(int64(len(spans)))
+	}
+	return err
 }
 
 func main() {
@@ -185,65 +616,76 @@ func main() {
 		Str("version", version).
 		Msg("starting hello-world application")
 
+	// One-shot rollback mode: step migrations down and exit without serving
+	migrateDownSteps := flag.Int("migrate-down", getIntEnv("MIGRATE_DOWN_STEPS", 0),
+		"roll back N migrations and exit (env MIGRATE_DOWN_STEPS)")
+	flag.Parse()
+	if *migrateDownSteps > 0 {
+		if err := runRollback(*migrateDownSteps); err != nil {
+			logger.Fatal().Err(err).Msg("migration rollback failed")
+		}
+		logger.Info().Int("steps", *migrateDownSteps).Msg("migration rollback complete")
+		return
+	}
+
 	// Feature flags defaults via env vars
 	metricsDefault := getBoolEnv("ENABLE_METRICS", false)
 	tracingDefault := getBoolEnv("ENABLE_TRACING", false)
 	adminFlagsEnabled := getBoolEnv("ADMIN_FLAGS_ENABLED", false)
+	shutdownTimeout := getDurationEnv("SHUTDOWN_TIMEOUT", 10*time.Second)
+	// Kept below the server WriteTimeout so the 503 can still be written
+	handlerTimeout := getDurationEnv("HANDLER_TIMEOUT", 25*time.Second)
 
 	// Initialize OpenFeature (flagd) client for dynamic flags
 	initFeatureFlags(tracingDefault, metricsDefault)
 
-	var (
-		db    *sql.DB
-		err   error
-		dbURL = os.Getenv("DATABASE_URL")
-	)
-	if dbURL != "" {
-		db, err = setupDatabase(dbURL)
-		if err != nil {
-			logger.Fatal().Err(err).Msg("database initialization failed")
-		}
-		defer func() {
-			if cerr := db.Close(); cerr != nil {
-				logger.Error().Err(cerr).Msg("database close error")
-			}
-		}()
-	} else {
-		logger.Info().Msg("DATABASE_URL not set, skipping database setup")
-	}
+	// Always register metrics collectors; recording/serving is gated dynamically.
+	// Registered before database setup so migrations can report their version.
+	var metricsRegistry *prometheus.Registry
+	mtr, metricsRegistry = enableMetrics()
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
-	defer shutdownTracerProvider(context.Background())
 	if tracingDefault {
-		ensureTracerProvider(ctx)
+		ensureTracerProvider()
 	}
 
-	// Always register metrics collectors; recording/serving is gated dynamically
-	mtr = enableMetrics()
-
-	checker := dependencyChecker{db: db}
+	checker := dependencyChecker{
+		db:    &atomic.Pointer[instrumentedDB]{},
+		query: strings.TrimSpace(os.Getenv("READINESS_DB_QUERY")),
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", helloHandler)
-	mux.HandleFunc("/readyz", checker.readinessHandler)
-	mux.HandleFunc("/livez", checker.livenessHandler)
+	// Each route is instrumented under its own pattern so metrics stay distinguishable.
+	// The timeout sits inside instrumentation so timed-out requests are counted as 503s.
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.Handle(pattern, instrumentHandler(pattern, timeoutMiddleware(handlerTimeout, h)))
+	}
+	handle("/", helloHandler)
+	handle("/readyz", checker.readinessHandler)
+	handle("/livez", checker.livenessHandler)
+	handle("/healthz", checker.healthzHandler)
+	handle("/version", versionHandler)
 
 	// Metrics endpoint gated dynamically per-request
-	promHandler := promhttp.Handler()
-	mux.Handle("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	promHandler := promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+	metricsPath, err := metricsPathFromEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid metrics path")
+	}
+	mux.Handle(metricsPath, metricsBasicAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !isMetricsEnabled(r.Context()) {
-			w.WriteHeader(http.StatusNotFound)
-			_, _ = w.Write([]byte("metrics disabled"))
+			writeJSONError(w, http.StatusNotFound, "metrics_disabled", "metrics disabled")
 			return
 		}
 		promHandler.ServeHTTP(w, r)
-	}))
+	})))
 
 	// Admin flags (local/dev): GET returns current; POST sets; POST /reset clears overrides
 	if adminFlagsEnabled {
-		mux.HandleFunc("/admin/flags", adminAuthMiddleware(adminFlagsHandler))
-		mux.HandleFunc("/admin/flags/reset", adminAuthMiddleware(adminFlagsResetHandler))
+		handle("/admin/flags", adminCORSMiddleware(adminAuthMiddleware(adminFlagsHandler)))
+		handle("/admin/flags/reset", adminCORSMiddleware(adminAuthMiddleware(adminFlagsResetHandler)))
+		handle("/admin/loglevel", adminCORSMiddleware(adminAuthMiddleware(adminLogLevelHandler)))
 		hasAuth := os.Getenv("ADMIN_API_KEY") != ""
 		if hasAuth {
 			logger.Info().Msg("Admin flags endpoint enabled with API key authentication: /admin/flags")
@@ -252,13 +694,29 @@ func main() {
 		}
 	}
 
-	addr := ":8080"
-	if p := os.Getenv("PORT"); p != "" {
-		addr = ":" + p
+	// Profiling endpoints (off by default); share the admin API key gate
+	pprofEnabled := getBoolEnv("ENABLE_PPROF", false)
+	if pprofEnabled {
+		registerPprof(mux)
+		logger.Info().Msg("pprof endpoints enabled with admin API key authentication: /debug/pprof/")
+	}
+
+	addr, err := listenAddr()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid listen address")
+	}
+	h2cEnabled := getBoolEnv("ENABLE_H2C", false)
+	maxBodyBytes, err := maxBodyBytesFromEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid request body limit")
+	}
+	handler := recoverMiddleware(securityHeaders(maxBodyMiddleware(maxBodyBytes, traceContextMiddleware(requestIDMiddleware(mux)))))
+	if h2cEnabled {
+		handler = withH2C(handler)
 	}
 	srv := &http.Server{
 		Addr:              addr,
-		Handler:           securityHeaders(mux),
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      30 * time.Second,
@@ -266,21 +724,49 @@ func main() {
 		MaxHeaderBytes:    1 << 20, // 1MB
 	}
 
+	certFile, _, err := tlsFilesFromEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid TLS configuration")
+	}
+	tlsEnabled := certFile != ""
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	// Serve before touching the database so probes get "migrations in progress"
+	// from /readyz rather than connection refused while migrations run.
 	serverErr := make(chan error, 1)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := listenAndServe(srv); err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
 		close(serverErr)
 	}()
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	defer signal.Stop(sigCh)
+	if dbURL := os.Getenv("DATABASE_URL"); dbURL != "" {
+		db, err := connectDatabase(ctx, checker, dbURL, setupDatabase)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("database initialization failed")
+		}
+		defer func() {
+			if cerr := db.Close(); cerr != nil {
+				logger.Error().Err(cerr).Msg("database close error")
+			}
+		}()
+	} else {
+		logger.Info().Msg("DATABASE_URL not set, skipping database setup")
+		migrationsComplete.Store(true)
+	}
 
 	logger.Info().
 		Str("addr", addr).
+		Bool("tls", tlsEnabled).
+		Bool("h2c", h2cEnabled).
+		Dur("shutdown_timeout", shutdownTimeout).
+		Dur("handler_timeout", handlerTimeout).
 		Bool("admin_flags_enabled", adminFlagsEnabled).
+		Bool("pprof_enabled", pprofEnabled).
 		Msg("server started")
 
 	select {
@@ -290,13 +776,131 @@ func main() {
 		}
 	case sig := <-sigCh:
 		logger.Info().Str("signal", sig.String()).Msg("received shutdown signal")
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		if err := srv.Shutdown(shutdownCtx); err != nil {
 			logger.Error().Err(err).Msg("server shutdown error")
 		}
 		cancel()
 		<-serverErr
 	}
+
+	// Flush traces only once srv.Shutdown has drained in-flight requests, so their
+	// spans have ended and been queued. Bounded so an unreachable collector cannot
+	// stall the exit past the pod's termination grace period.
+	tracerCtx, cancelTracer := context.WithTimeout(context.Background(), getDurationEnv("TRACER_SHUTDOWN_TIMEOUT", 5*time.Second))
+	shutdownTracerProvider(tracerCtx)
+	cancelTracer()
+}
+
+// registerPprof mounts the net/http/pprof handlers behind adminAuthMiddleware.
+func registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", adminAuthMiddleware(pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", adminAuthMiddleware(pprof.Cmdline))
+	mux.HandleFunc("/debug/pprof/profile", adminAuthMiddleware(pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", adminAuthMiddleware(pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", adminAuthMiddleware(pprof.Trace))
+}
+
+// withH2C accepts cleartext HTTP/2 (prior knowledge or Upgrade: h2c) in front
+// of h, while HTTP/1.1 clients that don't upgrade are passed through unchanged.
+// The wrapped middleware chain still runs for every HTTP/2 stream.
+func withH2C(h http.Handler) http.Handler {
+	return h2c.NewHandler(h, &http2.Server{IdleTimeout: 120 * time.Second})
+}
+
+// listenAddr combines BIND_ADDR (default all interfaces) and PORT (default 8080)
+// into a listen address, failing if the result is not a valid TCP address.
+func listenAddr() (string, error) {
+	addr := net.JoinHostPort(os.Getenv("BIND_ADDR"), getenvDefault("PORT", "8080"))
+	if _, err := net.ResolveTCPAddr("tcp", addr); err != nil {
+		return "", fmt.Errorf("parse listen address %q: %w", addr, err)
+	}
+	return addr, nil
+}
+
+// reservedPaths are registered by the server itself, and reservedPathPrefixes
+// cover the admin and profiling trees; METRICS_PATH may not shadow either.
+var (
+	reservedPaths        = []string{"/", "/readyz", "/livez", "/healthz", "/version"}
+	reservedPathPrefixes = []string{"/admin", "/debug"}
+)
+
+// metricsPathFromEnv returns METRICS_PATH (default /metrics), the path the
+// metrics handler is registered on. It must be a clean absolute URL path that
+// does not collide with another route.
+func metricsPathFromEnv() (string, error) {
+	p := getenvDefault("METRICS_PATH", "/metrics")
+	if !strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("METRICS_PATH %q must start with /", p)
+	}
+	if path.Clean(p) != p {
+		return "", fmt.Errorf("METRICS_PATH %q must be a clean path", p)
+	}
+	if slices.Contains(reservedPaths, p) {
+		return "", fmt.Errorf("METRICS_PATH %q is already served by another handler", p)
+	}
+	for _, prefix := range reservedPathPrefixes {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return "", fmt.Errorf("METRICS_PATH %q is reserved for %s endpoints", p, prefix)
+		}
+	}
+	return p, nil
+}
+
+// maxBodyBytesFromEnv returns MAX_BODY_BYTES (default 1 MiB), the request body
+// limit. It must be positive; a zero limit would reject every request body.
+func maxBodyBytesFromEnv() (int64, error) {
+	v := strings.TrimSpace(os.Getenv("MAX_BODY_BYTES"))
+	if v == "" {
+		return 1 << 20, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("MAX_BODY_BYTES %q must be a positive integer", v)
+	}
+	return n, nil
+}
+
+// tlsFilesFromEnv returns the certificate and key paths from TLS_CERT_FILE and
+// TLS_KEY_FILE. Both must be set together; neither set means plaintext HTTP.
+func tlsFilesFromEnv() (certFile, keyFile string, err error) {
+	certFile = os.Getenv("TLS_CERT_FILE")
+	keyFile = os.Getenv("TLS_KEY_FILE")
+	if (certFile == "") != (keyFile == "") {
+		return "", "", fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	return certFile, keyFile, nil
+}
+
+// listenAndServe serves HTTPS when TLS files are configured, plaintext HTTP otherwise.
+func listenAndServe(srv *http.Server) error {
+	certFile, keyFile, err := tlsFilesFromEnv()
+	if err != nil {
+		return err
+	}
+	if certFile != "" {
+		logger.Info().Str("cert_file", certFile).Msg("serving HTTPS")
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	}
+	logger.Info().Msg("serving plaintext HTTP")
+	return srv.ListenAndServe()
+}
+
+// connectDatabase runs setup (connect, then migrate unless SKIP_MIGRATIONS) and
+// hands the database to checker. Readiness stays gated until migrations have run
+// here or, with SKIP_MIGRATIONS, until the schema reaches the latest migration.
+func connectDatabase(ctx context.Context, checker dependencyChecker, dbURL string, setup func(string) (*sql.DB, error)) (*sql.DB, error) {
+	db, err := setup(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	checker.db.Store(newInstrumentedDB(db))
+	if getBoolEnv("SKIP_MIGRATIONS", false) {
+		go waitForSchema(ctx, db, getDurationEnv("MIGRATIONS_POLL_INTERVAL", 5*time.Second))
+		return db, nil
+	}
+	migrationsComplete.Store(true)
+	return db, nil
 }
 
 func setupDatabase(databaseURL string) (*sql.DB, error) {
@@ -306,8 +910,7 @@ func setupDatabase(databaseURL string) (*sql.DB, error) {
 	}
 
 	// Skip migrations if SKIP_MIGRATIONS=true (they should be run via Kubernetes Job)
-	skipMigrations := getBoolEnv("SKIP_MIGRATIONS", false)
-	if skipMigrations {
+	if getBoolEnv("SKIP_MIGRATIONS", false) {
 		logger.Info().Msg("SKIP_MIGRATIONS=true, migrations will not run in application")
 		return db, nil
 	}
@@ -319,6 +922,75 @@ func setupDatabase(databaseURL string) (*sql.DB, error) {
 	return db, nil
 }
 
+// waitForSchema polls the schema version written by the migrations Job and
+// marks migrations complete once it reaches the latest bundled migration
+// without being dirty.
+func waitForSchema(ctx context.Context, db *sql.DB, interval time.Duration) {
+	sourceURL, err := migrationsSourceURL()
+	if err != nil {
+		logger.Error().Err(err).Msg("migrations: cannot determine expected schema version")
+		return
+	}
+	latest, err := latestMigrationVersion(sourceURL)
+	if err != nil {
+		logger.Error().Err(err).Msg("migrations: cannot determine expected schema version")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		version, dirty, err := schemaVersion(ctx, db)
+		switch {
+		case err != nil:
+			logger.Warn().Err(err).Msg("migrations: unable to read schema version")
+		case !dirty && version >= int64(latest):
+			logger.Info().Int64("version", version).Msg("migrations: schema up to date")
+			migrationsComplete.Store(true)
+			return
+		default:
+			logger.Info().Int64("version", version).Bool("dirty", dirty).Uint("want", latest).Msg("migrations: waiting for schema")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// schemaVersion reads golang-migrate's version table without creating it, so
+// the app never races the migrations Job. A missing row reads as version -1.
+func schemaVersion(ctx context.Context, db *sql.DB) (version int64, dirty bool, err error) {
+	err = db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, false, nil
+	}
+	return version, dirty, err
+}
+
+// latestMigrationVersion returns the highest migration version in sourceURL.
+func latestMigrationVersion(sourceURL string) (uint, error) {
+	src, err := source.Open(sourceURL)
+	if err != nil {
+		return 0, fmt.Errorf("open migrations source: %w", err)
+	}
+	defer src.Close()
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("read first migration: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read migration after %d: %w", version, err)
+		}
+		version = next
+	}
+}
+
 func waitForDatabase(databaseURL string, timeout time.Duration) (*sql.DB, error) {
 	deadline := time.Now().Add(timeout)
 	for {
@@ -334,6 +1006,7 @@ func waitForDatabase(databaseURL string, timeout time.Duration) (*sql.DB, error)
 		pingErr := db.PingContext(ctx)
 		cancel()
 		if pingErr == nil {
+			configurePool(db)
 			return db, nil
 		}
 		db.Close()
@@ -344,24 +1017,121 @@ func waitForDatabase(databaseURL string, timeout time.Duration) (*sql.DB, error)
 	}
 }
 
-func runMigrations(db *sql.DB) error {
+// configurePool bounds the connection pool so load cannot exhaust Postgres connections.
+func configurePool(db *sql.DB) {
+	maxOpen := getIntEnv("DB_MAX_OPEN_CONNS", 25)
+	maxIdle := getIntEnv("DB_MAX_IDLE_CONNS", 5)
+	maxLifetime := getDurationEnv("DB_CONN_MAX_LIFETIME", 5*time.Minute)
+
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(maxLifetime)
+}
+
+// migrationsSourceURL builds the migrate source URL from MIGRATIONS_PATH (default /migrations).
+// Both file:// URLs and plain paths are accepted; relative paths resolve against the working directory.
+func migrationsSourceURL() (string, error) {
+	path := strings.TrimPrefix(getenvDefault("MIGRATIONS_PATH", "/migrations"), "file://")
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve migrations path %q: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("migrations path %q: %w", abs, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("migrations path %q is not a directory", abs)
+	}
+	return "file://" + abs, nil
+}
+
+// newMigrate builds a migrate instance for db using the configured migrations source.
+func newMigrate(db *sql.DB) (*migrate.Migrate, error) {
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
-		return fmt.Errorf("create driver: %w", err)
+		return nil, fmt.Errorf("create driver: %w", err)
+	}
+
+	sourceURL, err := migrationsSourceURL()
+	if err != nil {
+		return nil, err
 	}
 
-	m, err := migrate.NewWithDatabaseInstance("file:///migrations", "postgres", driver)
+	m, err := migrate.NewWithDatabaseInstance(sourceURL, "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("new migrate: %w", err)
+	}
+	return m, nil
+}
+
+func runMigrations(db *sql.DB) error {
+	m, err := newMigrate(db)
 	if err != nil {
-		return fmt.Errorf("new migrate: %w", err)
+		return err
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("migrate up: %w", err)
+	upErr := m.Up()
+	recordMigrationVersion(m)
+	if upErr != nil && upErr != migrate.ErrNoChange {
+		return fmt.Errorf("migrate up: %w", upErr)
 	}
-	if err == migrate.ErrNoChange {
+	if upErr == migrate.ErrNoChange {
 		logger.Info().Msg("migrations: no change")
 	} else {
 		logger.Info().Msg("migrations: applied successfully")
 	}
 	return nil
 }
+
+// runRollback connects to DATABASE_URL and rolls the schema back by steps migrations.
+func runRollback(steps int) error {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		return fmt.Errorf("DATABASE_URL is required to roll back migrations")
+	}
+	db, err := waitForDatabase(dbURL, 45*time.Second)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := newMigrate(db)
+	if err != nil {
+		return err
+	}
+
+	logMigrationVersion(m, "migrations: version before rollback")
+	if err := m.Steps(-steps); err != nil {
+		return fmt.Errorf("migrate down %d steps: %w", steps, err)
+	}
+	logMigrationVersion(m, "migrations: version after rollback")
+	return nil
+}
+
+// recordMigrationVersion publishes the schema version and dirty flag as gauges.
+func recordMigrationVersion(m *migrate.Migrate) {
+	if mtr == nil {
+		return
+	}
+	v, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		logger.Warn().Err(err).Msg("migrations: unable to read version")
+		return
+	}
+	mtr.migrationVersion.Set(float64(v))
+	if dirty {
+		mtr.migrationDirty.Set(1)
+	} else {
+		mtr.migrationDirty.Set(0)
+	}
+}
+
+func logMigrationVersion(m *migrate.Migrate, msg string) {
+	v, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		logger.Warn().Err(err).Msg("migrations: unable to read version")
+		return
+	}
+	logger.Info().Uint("version", v).Bool("dirty", dirty).Msg(msg)
+}
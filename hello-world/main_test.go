@@ -1,18 +1,34 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/open-feature/go-sdk/openfeature/memprovider"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http2"
 )
 
 func TestGetBoolEnv(t *testing.T) {
@@ -62,6 +78,7 @@ func TestTracingExportsAfterAdminEnable(t *testing.T) {
 	tracerInitialized.Store(false)
 	tracerInitMu.Lock()
 	tracerShutdownFn = nil
+	tracerInitErr = nil
 	tracerInitMu.Unlock()
 	shutdownTracerProvider(context.Background())
 
@@ -98,6 +115,7 @@ func TestTracingExportsAfterAdminEnable(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Fatalf("admin enable tracing returned status %d", rr.Code)
 	}
+	waitForTracer(t)
 
 	// Trigger handler which should now emit a span
 	helloReq := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -114,4 +132,913 @@ func TestTracingExportsAfterAdminEnable(t *testing.T) {
 	if spans[0].Name != "helloHandler" {
 		t.Fatalf("unexpected span name %q", spans[0].Name)
 	}
+
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["http.method"] != http.MethodGet || attrs["http.route"] != "/" || attrs["http.status_code"] != "200" {
+		t.Fatalf("unexpected span attributes %v", attrs)
+	}
+}
+
+func TestTLSFilesFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		cert    string
+		key     string
+		wantErr bool
+	}{
+		{name: "neither set", wantErr: false},
+		{name: "both set", cert: "/tls/tls.crt", key: "/tls/tls.key", wantErr: false},
+		{name: "cert only", cert: "/tls/tls.crt", wantErr: true},
+		{name: "key only", key: "/tls/tls.key", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TLS_CERT_FILE", tt.cert)
+			t.Setenv("TLS_KEY_FILE", tt.key)
+
+			certFile, keyFile, err := tlsFilesFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tlsFilesFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (certFile != tt.cert || keyFile != tt.key) {
+				t.Fatalf("tlsFilesFromEnv() = (%q,%q) want (%q,%q)", certFile, keyFile, tt.cert, tt.key)
+			}
+		})
+	}
+}
+
+func TestHealthzHandlerWithoutDatabase(t *testing.T) {
+	rr := httptest.NewRecorder()
+	dependencyChecker{}.healthzHandler(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("healthz status = %d want 200", rr.Code)
+	}
+
+	var body struct {
+		Status string                 `json:"status"`
+		Checks map[string]healthCheck `json:"checks"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("decode healthz body: %v", err)
+	}
+	if body.Status != "ok" {
+		t.Fatalf("status = %q want ok", body.Status)
+	}
+	if got := body.Checks["database"].Status; got != "disabled" {
+		t.Fatalf("database check = %q want disabled", got)
+	}
+}
+
+func TestReadinessGatedOnMigrations(t *testing.T) {
+	t.Cleanup(func() { migrationsComplete.Store(false) })
+
+	migrationsComplete.Store(false)
+	rr := httptest.NewRecorder()
+	dependencyChecker{}.readinessHandler(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz before migrations = %d want 503", rr.Code)
+	}
+
+	migrationsComplete.Store(true)
+	rr = httptest.NewRecorder()
+	dependencyChecker{}.readinessHandler(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("readyz after migrations = %d want 200", rr.Code)
+	}
+}
+
+func TestReadinessServedBeforeMigrationsFinish(t *testing.T) {
+	t.Setenv("SKIP_MIGRATIONS", "false")
+	t.Cleanup(func() { migrationsComplete.Store(false) })
+	migrationsComplete.Store(false)
+
+	checker := dependencyChecker{db: &atomic.Pointer[instrumentedDB]{}}
+	srv := httptest.NewServer(http.HandlerFunc(checker.readinessHandler))
+	defer srv.Close()
+	probe := func() int {
+		t.Helper()
+		resp, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("probe readyz: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := connectDatabase(context.Background(), checker, "postgres://db", func(string) (*sql.DB, error) {
+			<-release // migrations still running
+			return nil, nil
+		})
+		done <- err
+	}()
+
+	if code := probe(); code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz while migrating = %d want 503", code)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("connectDatabase() error = %v", err)
+	}
+	if code := probe(); code != http.StatusOK {
+		t.Fatalf("readyz after migrations = %d want 200", code)
+	}
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_a.up.sql", "0001_a.down.sql", "0003_b.up.sql", "0003_b.down.sql", "0010_c.up.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o600); err != nil {
+			t.Fatalf("write migration: %v", err)
+		}
+	}
+	got, err := latestMigrationVersion("file://" + dir)
+	if err != nil {
+		t.Fatalf("latestMigrationVersion() error = %v", err)
+	}
+	if got != 10 {
+		t.Fatalf("latestMigrationVersion() = %d want 10", got)
+	}
+
+	if _, err := latestMigrationVersion("file://" + t.TempDir()); err == nil {
+		t.Fatalf("expected error for an empty migrations directory")
+	}
+}
+
+func TestFlagProviderDisconnectedIsDegradedNotFatal(t *testing.T) {
+	t.Cleanup(func() {
+		featureFlagsInitialized.Store(false)
+		flagProviderConnected.Store(false)
+		migrationsComplete.Store(false)
+	})
+	featureFlagsInitialized.Store(true)
+	flagProviderConnected.Store(false)
+	migrationsComplete.Store(true)
+
+	rr := httptest.NewRecorder()
+	dependencyChecker{}.healthzHandler(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("healthz status = %d want 200", rr.Code)
+	}
+	var health struct {
+		Status string                 `json:"status"`
+		Checks map[string]healthCheck `json:"checks"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&health); err != nil {
+		t.Fatalf("decode healthz body: %v", err)
+	}
+	if health.Status != "ok" {
+		t.Fatalf("status = %q want ok", health.Status)
+	}
+	if got := health.Checks["feature_flags"].Status; got != "degraded" {
+		t.Fatalf("feature_flags check = %q want degraded", got)
+	}
+
+	rr = httptest.NewRecorder()
+	dependencyChecker{}.readinessHandler(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("readyz status = %d want 200", rr.Code)
+	}
+	var ready struct {
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&ready); err != nil {
+		t.Fatalf("decode readyz body: %v", err)
+	}
+	if len(ready.Warnings) != 1 {
+		t.Fatalf("readyz warnings = %v want one flag provider warning", ready.Warnings)
+	}
+}
+
+func TestMigrationsSourceURL(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "migrations"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	rel, err := filepath.Rel(wd, filepath.Join(dir, "migrations"))
+	if err != nil {
+		t.Fatalf("rel: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "absolute path", path: dir + "/migrations", want: "file://" + dir + "/migrations"},
+		{name: "file url", path: "file://" + dir + "/migrations", want: "file://" + dir + "/migrations"},
+		{name: "relative path", path: rel, want: "file://" + dir + "/migrations"},
+		{name: "missing path", path: dir + "/missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MIGRATIONS_PATH", tt.path)
+			got, err := migrationsSourceURL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("migrationsSourceURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("migrationsSourceURL() = %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListenAddr(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindAddr string
+		port     string
+		want     string
+		wantErr  bool
+	}{
+		{name: "defaults", want: ":8080"},
+		{name: "port only", port: "9090", want: ":9090"},
+		{name: "loopback", bindAddr: "127.0.0.1", port: "9090", want: "127.0.0.1:9090"},
+		{name: "ipv6", bindAddr: "::1", want: "[::1]:8080"},
+		{name: "invalid port", port: "http-alt-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BIND_ADDR", tt.bindAddr)
+			t.Setenv("PORT", tt.port)
+			got, err := listenAddr()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listenAddr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("listenAddr() = %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxBodyBytesFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "default", want: 1 << 20},
+		{name: "custom", value: "4096", want: 4096},
+		{name: "zero", value: "0", wantErr: true},
+		{name: "negative", value: "-1", wantErr: true},
+		{name: "not a number", value: "1MB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MAX_BODY_BYTES", tt.value)
+			got, err := maxBodyBytesFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("maxBodyBytesFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("maxBodyBytesFromEnv() = %d want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsPathFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: "/metrics"},
+		{name: "custom", value: "/internal/prom", want: "/internal/prom"},
+		{name: "relative", value: "metrics", wantErr: true},
+		{name: "root", value: "/", wantErr: true},
+		{name: "healthz", value: "/healthz", wantErr: true},
+		{name: "readyz", value: "/readyz", wantErr: true},
+		{name: "livez", value: "/livez", wantErr: true},
+		{name: "version", value: "/version", wantErr: true},
+		{name: "admin", value: "/admin", wantErr: true},
+		{name: "admin subtree", value: "/admin/metrics", wantErr: true},
+		{name: "pprof", value: "/debug/pprof/metrics", wantErr: true},
+		{name: "unclean", value: "/healthz/../metrics", wantErr: true},
+		{name: "trailing slash", value: "/metrics/", wantErr: true},
+		{name: "admin lookalike", value: "/administrator", want: "/administrator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("METRICS_PATH", tt.value)
+			got, err := metricsPathFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("metricsPathFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("metricsPathFromEnv() = %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithH2C(t *testing.T) {
+	srv := httptest.NewServer(withH2C(securityHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))))
+	defer srv.Close()
+
+	h2 := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	for name, client := range map[string]*http.Client{"h2c": h2, "http/1.1": srv.Client()} {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("%s: get: %v", name, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		want := "HTTP/1.1"
+		if name == "h2c" {
+			want = "HTTP/2.0"
+		}
+		if string(body) != want {
+			t.Fatalf("%s: proto = %q want %q", name, body, want)
+		}
+		if resp.Header.Get("X-Content-Type-Options") != "nosniff" {
+			t.Fatalf("%s: security headers missing", name)
+		}
+	}
+}
+
+func TestParseBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []float64
+		wantErr bool
+	}{
+		{name: "sorted", in: "0.001,0.005,0.01", want: []float64{0.001, 0.005, 0.01}},
+		{name: "unsorted with spaces", in: "0.1, 0.01 ,1", want: []float64{0.01, 0.1, 1}},
+		{name: "not a number", in: "0.1,fast", wantErr: true},
+		{name: "negative", in: "-1,0.1", wantErr: true},
+		{name: "duplicate", in: "0.1,0.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBuckets(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBuckets(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseBuckets(%q) = %v want %v", tt.in, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("parseBuckets(%q) = %v want %v", tt.in, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewTraceExporterRejectsUnknownProtocol(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "carrier-pigeon")
+	if _, err := newTraceExporter(context.Background()); err == nil {
+		t.Fatalf("expected error for unknown protocol")
+	}
+}
+
+func TestTraceSampleRatio(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{value: "", want: 1.0},
+		{value: "0.25", want: 0.25},
+		{value: "1.5", want: 1.0},
+		{value: "-0.1", want: 0},
+		{value: "half", want: 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_SAMPLER_ARG", tt.value)
+			if got := traceSampleRatio(); got != tt.want {
+				t.Fatalf("traceSampleRatio() = %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTraceResourceMergesEnvAttributes(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("ENVIRONMENT", "staging")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "team=platform,region=eu-west-1")
+
+	res, err := newTraceResource(context.Background())
+	if err != nil {
+		t.Fatalf("newTraceResource: %v", err)
+	}
+	attrs := map[string]string{}
+	for _, kv := range res.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	want := map[string]string{
+		"service.name": "hello-world",
+		"env":          "staging",
+		"team":         "platform",
+		"region":       "eu-west-1",
+	}
+	for k, v := range want {
+		if attrs[k] != v {
+			t.Fatalf("attribute %s = %q want %q (all: %v)", k, attrs[k], v, attrs)
+		}
+	}
+}
+
+func TestCountingExporterCountsFlushedSpans(t *testing.T) {
+	counted := &countingExporter{SpanExporter: tracetest.NewInMemoryExporter()}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(counted, sdktrace.WithBatchTimeout(time.Hour)))
+	for i := 0; i < 3; i++ {
+		_, span := tp.Tracer("test").Start(context.Background(), "op")
+		span.End()
+	}
+	if got := counted.exported.Load(); got != 0 {
+		t.Fatalf("exported before shutdown = %d want 0", got)
+	}
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if got := counted.exported.Load(); got != 3 {
+		t.Fatalf("exported after shutdown = %d want 3", got)
+	}
+}
+
+// waitForTracer waits for a background tracer provider init to finish.
+func waitForTracer(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !tracerInitialized.Load() {
+		if time.Now().After(deadline) {
+			t.Fatalf("tracer provider not initialized")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEnsureTracerProviderDoesNotBlock(t *testing.T) {
+	defer func() {
+		tracerProviderFactory = initTracer
+		tracerInitMu.Lock()
+		tracerInitErr = nil
+		tracerInitMu.Unlock()
+	}()
+	shutdownTracerProvider(context.Background())
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	tracerProviderFactory = func(ctx context.Context) (func(context.Context) error, error) {
+		calls.Add(1)
+		<-release
+		return nil, errors.New("unsupported protocol")
+	}
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		ensureTracerProvider()
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("ensureTracerProvider blocked for %v", elapsed)
+	}
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for tracerInitInFlight.Load() {
+		if time.Now().After(deadline) {
+			t.Fatalf("background tracer init did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("factory calls = %d want 1 (concurrent callers share one init)", got)
+	}
+
+	// A configuration error is not retried.
+	ensureTracerProvider()
+	if tracerInitInFlight.Load() || calls.Load() != 1 {
+		t.Fatalf("tracer init retried after a configuration error")
+	}
+}
+
+func TestNewTraceExporterDoesNotDialCollector(t *testing.T) {
+	// Nothing listens on port 1; creating the exporter must still succeed.
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:1")
+	for _, protocol := range []string{"http/protobuf", "grpc"} {
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", protocol)
+		exp, err := newTraceExporter(context.Background())
+		if err != nil {
+			t.Fatalf("newTraceExporter(%s) error = %v", protocol, err)
+		}
+		_ = exp.Shutdown(context.Background())
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "carrier-pigeon")
+	if _, err := newTraceExporter(context.Background()); err == nil {
+		t.Fatalf("expected an error for an unsupported protocol")
+	}
+}
+
+func TestAdminFlagsListsAllFlags(t *testing.T) {
+	openfeature.SetProvider(openfeature.NewNoopProvider())
+	ofClient = openfeature.NewClient("test")
+	defaultTracing.Store(false)
+	defaultMetrics.Store(true)
+	enabled := true
+	overridesValue.Store(flagOverrides{Tracing: &enabled})
+	defer overridesValue.Store(flagOverrides{})
+
+	rr := httptest.NewRecorder()
+	adminFlagsHandler(rr, httptest.NewRequest(http.MethodGet, "/admin/flags", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d want 200", rr.Code)
+	}
+
+	var body struct {
+		Flags map[string]flagState `json:"flags"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	tracing := body.Flags["tracing"]
+	if tracing.Default || tracing.Override == nil || !*tracing.Override || !tracing.Effective {
+		t.Fatalf("tracing state = %+v", tracing)
+	}
+	metrics := body.Flags["metrics"]
+	if !metrics.Default || metrics.Override != nil || !metrics.Effective {
+		t.Fatalf("metrics state = %+v", metrics)
+	}
+
+	rr = httptest.NewRecorder()
+	adminFlagsHandler(rr, httptest.NewRequest(http.MethodGet, "/admin/flags?flag=unknown", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown flag status = %d want 404", rr.Code)
+	}
+}
+
+func TestAdminFlagsRejectsOversizedBody(t *testing.T) {
+	defer overridesValue.Store(flagOverrides{})
+	h := maxBodyMiddleware(1<<20, http.HandlerFunc(adminFlagsHandler))
+
+	for name, size := range map[string]int{
+		"over global limit":  1<<20 + 1,
+		"over handler limit": 2048,
+	} {
+		body := `{"tracing": true, "pad": "` + strings.Repeat("x", size) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/admin/flags", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("%s: status = %d want 413", name, rr.Code)
+		}
+	}
+}
+
+func TestAdminCORSMiddleware(t *testing.T) {
+	t.Setenv("ADMIN_API_KEY", "secret")
+	t.Setenv("ADMIN_CORS_ORIGINS", "https://ui.example.com, https://other.example.com")
+	h := adminCORSMiddleware(adminAuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/admin/flags", nil)
+	preflight.Header.Set("Origin", "https://ui.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rr := httptest.NewRecorder()
+	h(rr, preflight)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d want 204", rr.Code)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://ui.example.com" {
+		t.Fatalf("Access-Control-Allow-Origin = %q", got)
+	}
+	if !strings.Contains(rr.Header().Get("Access-Control-Allow-Headers"), "Authorization") {
+		t.Fatalf("Access-Control-Allow-Headers = %q", rr.Header().Get("Access-Control-Allow-Headers"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/flags", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("disallowed origin status = %d want 200", rr.Code)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("disallowed origin got Access-Control-Allow-Origin %q", got)
+	}
+
+	t.Setenv("ADMIN_CORS_ORIGINS", "")
+	rr = httptest.NewRecorder()
+	adminCORSMiddleware(adminAuthMiddleware(func(w http.ResponseWriter, r *http.Request) {}))(rr, preflight)
+	if rr.Code == http.StatusNoContent || rr.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("CORS disabled but preflight answered: status %d", rr.Code)
+	}
+}
+
+func TestFlagOverridesPersistence(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "overrides.json")
+	t.Setenv("ADMIN_FLAGS_STORE", store)
+	defer overridesValue.Store(flagOverrides{})
+
+	if ov := loadOverrides(store); ov.Tracing != nil || ov.Metrics != nil {
+		t.Fatalf("missing store loaded overrides %+v", ov)
+	}
+
+	overridesValue.Store(flagOverrides{})
+	req := httptest.NewRequest(http.MethodPost, "/admin/flags?tracing=true", nil)
+	rr := httptest.NewRecorder()
+	adminFlagsHandler(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST status = %d want 200", rr.Code)
+	}
+	ov := loadOverrides(store)
+	if ov.Tracing == nil || !*ov.Tracing || ov.Metrics != nil {
+		t.Fatalf("persisted overrides = %+v want tracing=true", ov)
+	}
+
+	if err := os.WriteFile(store, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if ov := loadOverrides(store); ov.Tracing != nil || ov.Metrics != nil {
+		t.Fatalf("corrupt store loaded overrides %+v", ov)
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	t.Setenv("ADMIN_API_KEY", "s3cret")
+	ok := adminAuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{name: "valid bearer", header: "Authorization", value: "Bearer s3cret", want: http.StatusNoContent},
+		{name: "wrong bearer", header: "Authorization", value: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "missing scheme", header: "Authorization", value: "s3cret", want: http.StatusUnauthorized},
+		{name: "legacy header rejected", header: "X-Admin-API-Key", value: "s3cret", want: http.StatusUnauthorized},
+		{name: "no credentials", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/flags", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rr := httptest.NewRecorder()
+			ok(rr, req)
+			if rr.Code != tt.want {
+				t.Fatalf("status = %d want %d", rr.Code, tt.want)
+			}
+			if rr.Code == http.StatusUnauthorized && rr.Body.Len() != 0 {
+				t.Fatalf("401 body should be empty, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestIPRateLimiter(t *testing.T) {
+	l := newIPRateLimiter(1) // burst of 2
+	if !l.allow("10.0.0.1") || !l.allow("10.0.0.1") {
+		t.Fatalf("expected burst requests to be allowed")
+	}
+	if l.allow("10.0.0.1") {
+		t.Fatalf("expected third immediate request to be limited")
+	}
+	if !l.allow("10.0.0.2") {
+		t.Fatalf("other IPs should have their own bucket")
+	}
+}
+
+func TestIPRateLimiterEvictsLeastRecentlySeen(t *testing.T) {
+	l := newIPRateLimiter(0.001) // burst of 1, effectively no refill
+	if !l.allow("10.0.0.1") || l.allow("10.0.0.1") {
+		t.Fatalf("expected 10.0.0.1 to exhaust its burst")
+	}
+	// Flood the limiter with new IPs while 10.0.0.1 keeps retrying.
+	for i := 0; i < 2*maxTrackedIPs; i++ {
+		l.allow("client-" + strconv.Itoa(i))
+		if i%100 == 0 && l.allow("10.0.0.1") {
+			t.Fatalf("10.0.0.1 bucket was reset after %d new IPs", i)
+		}
+	}
+	if got := len(l.limiters); got > maxTrackedIPs {
+		t.Fatalf("tracked %d IPs, want at most %d", got, maxTrackedIPs)
+	}
+	if l.allow("10.0.0.1") {
+		t.Fatalf("10.0.0.1 bucket was reset by the flood")
+	}
+}
+
+func TestLoggerFromContextAddsSpanEvents(t *testing.T) {
+	prev := logger
+	logger = zerolog.New(io.Discard)
+	defer func() { logger = prev }()
+
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sdktrace.NewSimpleSpanProcessor(exp)))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	loggerFromContext(ctx).Info().Msg("not mirrored")
+	loggerFromContext(ctx).Error().Msg("something failed")
+	span.End()
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	events := spans[0].Events
+	if len(events) != 1 || events[0].Name != "something failed" {
+		t.Fatalf("unexpected span events %+v", events)
+	}
+}
+
+func TestLoggerFromContextAddsBaggage(t *testing.T) {
+	var buf bytes.Buffer
+	prev, prevKeys := logger, logBaggageKeys
+	logger = zerolog.New(&buf)
+	logBaggageKeys = []string{"tenant.id", "absent"}
+	defer func() { logger, logBaggageKeys = prev, prevKeys }()
+
+	h := traceContextMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loggerFromContext(r.Context()).Info().Msg("handled")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("baggage", "tenant.id=acme,other=ignored")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("decode log line %q: %v", buf.String(), err)
+	}
+	if line["tenant.id"] != "acme" {
+		t.Fatalf("tenant.id = %v want acme (line %v)", line["tenant.id"], line)
+	}
+	if _, ok := line["other"]; ok {
+		t.Fatalf("unselected baggage member logged: %v", line)
+	}
+	if _, ok := line["absent"]; ok {
+		t.Fatalf("missing baggage member logged: %v", line)
+	}
+}
+
+func TestAdminLogLevelHandler(t *testing.T) {
+	prev := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(prev)
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(`{"level":"debug"}`))
+	rr := httptest.NewRecorder()
+	adminLogLevelHandler(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d want 200", rr.Code)
+	}
+	if zerolog.GlobalLevel() != zerolog.DebugLevel {
+		t.Fatalf("global level = %s want debug", zerolog.GlobalLevel())
+	}
+	if !strings.Contains(rr.Body.String(), `"level":"debug"`) {
+		t.Fatalf("unexpected body %q", rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(`{"level":"loud"}`))
+	rr = httptest.NewRecorder()
+	adminLogLevelHandler(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("invalid level status = %d want 400", rr.Code)
+	}
+	if zerolog.GlobalLevel() != zerolog.DebugLevel {
+		t.Fatalf("invalid level should not change global level")
+	}
+}
+
+func TestLogLevelFlagAppliedOutsideLogCalls(t *testing.T) {
+	prev := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(prev)
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	lastLogLevelFlag.Store("")
+
+	provider := memprovider.NewInMemoryProvider(map[string]memprovider.InMemoryFlag{
+		"log.level": {
+			Key:            "log.level",
+			State:          memprovider.Enabled,
+			DefaultVariant: "debug",
+			Variants:       map[string]any{"debug": "debug"},
+		},
+	})
+	if err := openfeature.SetProviderAndWait(provider); err != nil {
+		t.Fatalf("SetProviderAndWait: %v", err)
+	}
+	defer openfeature.SetProvider(openfeature.NewNoopProvider())
+	ofClient = openfeature.NewClient("test")
+	featureFlagsInitialized.Store(true)
+	defer featureFlagsInitialized.Store(false)
+
+	loggerFromContext(context.Background())
+	if zerolog.GlobalLevel() != zerolog.InfoLevel {
+		t.Fatalf("loggerFromContext changed the global level to %s", zerolog.GlobalLevel())
+	}
+
+	applyLogLevelFlag(context.Background())
+	if zerolog.GlobalLevel() != zerolog.DebugLevel {
+		t.Fatalf("global level = %s want debug after applying the flag", zerolog.GlobalLevel())
+	}
+}
+
+func TestSamplePercentFlag(t *testing.T) {
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	defer sampleRatioBits.Store(0)
+	ratio := func() float64 { return math.Float64frombits(sampleRatioBits.Load()) }
+
+	openfeature.SetProvider(openfeature.NewNoopProvider())
+	ofClient = openfeature.NewClient("test")
+	applySamplePercentFlag(context.Background())
+	if got := ratio(); got != 0.25 {
+		t.Fatalf("ratio without flag = %v want 0.25 from OTEL_TRACES_SAMPLER_ARG", got)
+	}
+
+	provider := memprovider.NewInMemoryProvider(map[string]memprovider.InMemoryFlag{
+		"tracing.sample_percent": {
+			Key:            "tracing.sample_percent",
+			State:          memprovider.Enabled,
+			DefaultVariant: "none",
+			Variants:       map[string]any{"none": int64(0)},
+		},
+	})
+	if err := openfeature.SetProviderAndWait(provider); err != nil {
+		t.Fatalf("SetProviderAndWait: %v", err)
+	}
+	defer openfeature.SetProvider(openfeature.NewNoopProvider())
+	ofClient = openfeature.NewClient("test")
+	applySamplePercentFlag(context.Background())
+	if got := ratio(); got != 0 {
+		t.Fatalf("ratio with flag = %v want 0", got)
+	}
+
+	res := flagRatioSampler{}.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: context.Background(),
+		TraceID:       trace.TraceID{0xff},
+		Name:          "root",
+	})
+	if res.Decision != sdktrace.Drop {
+		t.Fatalf("sampling decision = %v want Drop at 0%%", res.Decision)
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	prevVersion, prevBuildTime := version, buildTime
+	defer func() { version, buildTime = prevVersion, prevBuildTime }()
+	version, buildTime = "v1.2.3", "2024-01-01T00:00:00Z"
+
+	rr := httptest.NewRecorder()
+	securityHeaders(http.HandlerFunc(versionHandler)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d want 200", rr.Code)
+	}
+	if rr.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("expected security headers on /version")
+	}
+	var got versionInfo
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	want := versionInfo{Version: "v1.2.3", GoVersion: runtime.Version(), BuildTime: "2024-01-01T00:00:00Z"}
+	if got != want {
+		t.Fatalf("body = %+v want %+v", got, want)
+	}
+
+	rr = httptest.NewRecorder()
+	versionHandler(rr, httptest.NewRequest(http.MethodPost, "/version", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d want 405", rr.Code)
+	}
 }
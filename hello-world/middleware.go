@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// propagator extracts W3C trace context and baggage from incoming requests. It is
+// also installed as the global propagator when the tracer provider initializes.
+var propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds incoming X-Request-ID values that are reused as-is.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+type routeKey struct{}
+
+// requestIDMiddleware tags each request with a correlation ID, reusing a valid
+// incoming X-Request-ID header or generating a UUID, and echoes it back on the response.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID reports whether a client-supplied ID is short and made only of
+// HTTP token characters, so it is safe to echo and log verbatim.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDFromContext returns the request ID stored by requestIDMiddleware, if any.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// traceContextMiddleware extracts an upstream traceparent header into the request context
+// so spans chain to the caller's trace and loggerFromContext reports its trace ID.
+func traceContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// statusRecorder captures the status code written by a handler.
+// The status defaults to 200 when the handler never calls WriteHeader.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// routeFromContext returns the route pattern stored by instrumentHandler, if any.
+func routeFromContext(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
+
+// instrumentHandler records request count, latency and in-flight requests for next
+// under the given route label. The in-flight gauge is decremented even if next panics.
+// The route is also stored on the request context for span attributes.
+func instrumentHandler(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), routeKey{}, route))
+
+		m := mtr
+		if m == nil || !isMetricsEnabled(r.Context()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		inFlight := m.reqInFlight.WithLabelValues(route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		rec := newStatusRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		m.reqCount.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		m.reqDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// metricsBasicAuth requires HTTP Basic credentials matching
+// METRICS_BASIC_AUTH_USER and METRICS_BASIC_AUTH_PASS. Unless both are set the
+// endpoint stays open and next is returned unchanged.
+func metricsBasicAuth(next http.Handler) http.Handler {
+	user, pass := os.Getenv("METRICS_BASIC_AUTH_USER"), os.Getenv("METRICS_BASIC_AUTH_PASS")
+	if user == "" || pass == "" {
+		if user != "" || pass != "" {
+			logger.Warn().Msg("only one of METRICS_BASIC_AUTH_USER/METRICS_BASIC_AUTH_PASS set, metrics basic auth disabled")
+		}
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+		// Compare both fields unconditionally so timing reveals neither which field was wrong.
+		userOK := subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(p), []byte(pass)) == 1
+		if !ok || !userOK || !passOK {
+			w.Header().Set("WWW-Authenticate", `Basic realm="metrics", charset="UTF-8"`)
+			writeJSONError(w, http.StatusUnauthorized, "unauthorized", "metrics require authentication")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxBodyMiddleware caps request bodies at limit bytes. Requests that declare a
+// larger Content-Length are rejected up front; otherwise reads past the limit
+// fail with *http.MaxBytesError, which handlers should map to 413.
+func maxBodyMiddleware(limit int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			writeJSONError(w, http.StatusRequestEntityTooLarge, "body_too_large", "request body too large")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// timeoutBody mirrors the writeJSONError envelope. It is pre-encoded because
+// http.TimeoutHandler writes its message verbatim.
+const timeoutBody = `{"error":{"code":"timeout","message":"request timed out"}}` + "\n"
+
+// timeoutMiddleware bounds next with http.TimeoutHandler so a slow handler
+// answers 503 instead of holding the connection until WriteTimeout. Wrap it
+// inside instrumentHandler so the timeout status is what gets counted.
+// TimeoutHandler runs next on its own goroutine and re-panics on the caller's,
+// so panics are recovered inside it to log the failing handler's stack.
+func timeoutMiddleware(timeout time.Duration, next http.Handler) http.Handler {
+	th := http.TimeoutHandler(recoverMiddleware(next), timeout, timeoutBody)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		th.ServeHTTP(&timeoutResponseWriter{ResponseWriter: w}, r)
+	})
+}
+
+// timeoutResponseWriter marks TimeoutHandler's bare 503 as JSON. Responses that
+// finish in time carry the handler's own headers and are left untouched.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *timeoutResponseWriter) WriteHeader(code int) {
+	if code == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// recoverMiddleware turns handler panics into 500 responses so a single bad request
+// cannot crash the server. http.ErrAbortHandler is re-panicked to keep its semantics.
+func recoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rv := recover()
+			if rv == nil {
+				return
+			}
+			if rv == http.ErrAbortHandler {
+				panic(rv)
+			}
+			if mtr != nil {
+				mtr.panics.Inc()
+			}
+			loggerFromContext(r.Context()).Error().
+				Interface("panic", rv).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Bytes("stack", debug.Stack()).
+				Msg("recovered from handler panic")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
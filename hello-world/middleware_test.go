@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(requestIDHeader, "abc-123")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if seen != "abc-123" {
+		t.Fatalf("context request id = %q want abc-123", seen)
+	}
+	if got := rr.Header().Get(requestIDHeader); got != "abc-123" {
+		t.Fatalf("response %s = %q want abc-123", requestIDHeader, got)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if seen == "" || seen == "abc-123" {
+		t.Fatalf("expected a generated request id, got %q", seen)
+	}
+	if got := rr.Header().Get(requestIDHeader); got != seen {
+		t.Fatalf("response %s = %q want %q", requestIDHeader, got, seen)
+	}
+}
+
+func TestRequestIDMiddlewareRejectsUnsafeIDs(t *testing.T) {
+	var seen string
+	h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestIDFromContext(r.Context())
+	}))
+
+	for _, id := range []string{
+		strings.Repeat("a", maxRequestIDLen+1),
+		"has space",
+		"quote\"d",
+		"new\tline",
+		"caf\u00e9",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(requestIDHeader, id)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if seen == id || seen == "" {
+			t.Fatalf("request id %q = %q, want a generated id", id, seen)
+		}
+		if got := rr.Header().Get(requestIDHeader); got != seen {
+			t.Fatalf("response %s = %q want %q", requestIDHeader, got, seen)
+		}
+	}
+
+	longest := strings.Repeat("a", maxRequestIDLen)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(requestIDHeader, longest)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if seen != longest {
+		t.Fatalf("request id at the length limit was replaced with %q", seen)
+	}
+}
+
+func TestSecurityHeadersOverrides(t *testing.T) {
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	rr := httptest.NewRecorder()
+	securityHeaders(noop).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rr.Header().Get("Content-Security-Policy"); got != "default-src 'none'" {
+		t.Fatalf("default CSP = %q", got)
+	}
+	if got := rr.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Fatalf("default X-Frame-Options = %q", got)
+	}
+
+	t.Setenv("CSP_HEADER", "")
+	t.Setenv("FRAME_OPTIONS", "SAMEORIGIN")
+	rr = httptest.NewRecorder()
+	securityHeaders(noop).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if _, ok := rr.Header()["Content-Security-Policy"]; ok {
+		t.Fatalf("empty CSP_HEADER should omit Content-Security-Policy")
+	}
+	if got := rr.Header().Get("X-Frame-Options"); got != "SAMEORIGIN" {
+		t.Fatalf("X-Frame-Options = %q want SAMEORIGIN", got)
+	}
+}
+
+// useTestMetrics installs unregistered collectors with metrics enabled via override.
+func useTestMetrics(t *testing.T) *appMetrics {
+	t.Helper()
+	openfeature.SetProvider(openfeature.NewNoopProvider())
+	ofClient = openfeature.NewClient("test")
+	enabled := true
+	overridesValue.Store(flagOverrides{Metrics: &enabled})
+
+	m := &appMetrics{
+		reqCount: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_requests_total"},
+			[]string{"handler", "method", "status"}),
+		reqDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_duration_seconds"},
+			[]string{"handler", "method"}),
+		reqInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_in_flight"},
+			[]string{"handler"}),
+		panics: prometheus.NewCounter(prometheus.CounterOpts{Name: "test_panics_total"}),
+		flagEvaluations: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_flag_evaluations_total"},
+			[]string{"flag", "value"}),
+	}
+	prev := mtr
+	mtr = m
+	t.Cleanup(func() {
+		mtr = prev
+		overridesValue.Store(flagOverrides{})
+	})
+	return m
+}
+
+func TestInstrumentHandlerRecordsStatus(t *testing.T) {
+	m := useTestMetrics(t)
+
+	notFound := instrumentHandler("/missing", http.NotFoundHandler())
+	notFound.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	implicitOK := instrumentHandler("/ok", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	implicitOK.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+	if got := testutil.ToFloat64(m.reqCount.WithLabelValues("/missing", http.MethodGet, "404")); got != 1 {
+		t.Fatalf(`http_requests_total{status="404"} = %v want 1`, got)
+	}
+	if got := testutil.ToFloat64(m.reqCount.WithLabelValues("/ok", http.MethodGet, "200")); got != 1 {
+		t.Fatalf(`http_requests_total{status="200"} = %v want 1`, got)
+	}
+}
+
+func TestInstrumentHandlerInFlightAfterPanic(t *testing.T) {
+	m := useTestMetrics(t)
+
+	var during float64
+	h := instrumentHandler("/panic", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		during = testutil.ToFloat64(m.reqInFlight.WithLabelValues("/panic"))
+		panic("boom")
+	}))
+
+	func() {
+		defer func() { _ = recover() }()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}()
+
+	if during != 1 {
+		t.Fatalf("in-flight during request = %v want 1", during)
+	}
+	if got := testutil.ToFloat64(m.reqInFlight.WithLabelValues("/panic")); got != 0 {
+		t.Fatalf("in-flight after panic = %v want 0", got)
+	}
+}
+
+func TestTimeoutMiddlewareCountsTimeouts(t *testing.T) {
+	m := useTestMetrics(t)
+
+	h := instrumentHandler("/slow", timeoutMiddleware(10*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d want 503", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q want application/json", ct)
+	}
+	var body errorBody
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Error.Code != "timeout" {
+		t.Fatalf("error code = %q want timeout", body.Error.Code)
+	}
+	if got := testutil.ToFloat64(m.reqCount.WithLabelValues("/slow", http.MethodGet, "503")); got != 1 {
+		t.Fatalf(`http_requests_total{status="503"} = %v want 1`, got)
+	}
+}
+
+func TestMetricsBasicAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	metricsBasicAuth(ok).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("unset credentials: status = %d want 200", rr.Code)
+	}
+
+	t.Setenv("METRICS_BASIC_AUTH_USER", "prom")
+	t.Setenv("METRICS_BASIC_AUTH_PASS", "s3cret")
+	h := metricsBasicAuth(ok)
+
+	tests := []struct {
+		name       string
+		user, pass string
+		setAuth    bool
+		want       int
+	}{
+		{name: "missing", want: http.StatusUnauthorized},
+		{name: "wrong password", user: "prom", pass: "nope", setAuth: true, want: http.StatusUnauthorized},
+		{name: "wrong user", user: "other", pass: "s3cret", setAuth: true, want: http.StatusUnauthorized},
+		{name: "valid", user: "prom", pass: "s3cret", setAuth: true, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tt.setAuth {
+			req.SetBasicAuth(tt.user, tt.pass)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != tt.want {
+			t.Fatalf("%s: status = %d want %d", tt.name, rr.Code, tt.want)
+		}
+		if tt.want == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+			t.Fatalf("%s: missing WWW-Authenticate header", tt.name)
+		}
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	h := recoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/explode", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d want 500", rr.Code)
+	}
+
+	// The server keeps serving after a recovered panic
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/explode", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("second request status = %d want 500", rr.Code)
+	}
+}
+
+func panickingTestHandler(w http.ResponseWriter, r *http.Request) {
+	panic("boom")
+}
+
+func TestTimeoutMiddlewareLogsPanickingHandlerStack(t *testing.T) {
+	var buf bytes.Buffer
+	prev := logger
+	logger = zerolog.New(&buf)
+	defer func() { logger = prev }()
+
+	h := recoverMiddleware(timeoutMiddleware(time.Second, http.HandlerFunc(panickingTestHandler)))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/explode", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d want 500", rr.Code)
+	}
+
+	var stack string
+	for _, raw := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var line struct {
+			Stack string `json:"stack"`
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			t.Fatalf("decode log line %q: %v", raw, err)
+		}
+		if line.Stack != "" {
+			stack = line.Stack
+		}
+	}
+	if !strings.Contains(stack, "panickingTestHandler") {
+		t.Fatalf("logged stack lacks the panicking handler frame:\n%s", stack)
+	}
+}
+
+func TestTraceContextMiddlewareExtractsParent(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	var got string
+	h := traceContextMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = trace.SpanContextFromContext(r.Context()).TraceID().String()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got != traceID {
+		t.Fatalf("trace id = %q want %q", got, traceID)
+	}
+}
+
+func TestWriteJSONError(t *testing.T) {
+	rr := httptest.NewRecorder()
+	writeJSONError(rr, http.StatusServiceUnavailable, "not_ready", "database unavailable")
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d want 503", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	var body errorBody
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Error.Code != "not_ready" || body.Error.Message != "database unavailable" {
+		t.Fatalf("unexpected body %+v", body)
+	}
+}